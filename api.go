@@ -1,8 +1,13 @@
 package customerio
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 )
 
 type HTTPClient interface {
@@ -18,11 +23,56 @@ type APIClient struct {
 	UserAgent string
 	// Deprecated: Use NewAPIClient with WithHTTPClient instead. Will be unexported in v4.
 	Client HTTPClient
+
+	creds           CredentialsProvider
+	recipients      *RecipientPolicy
+	triggerInterval time.Duration
 }
 
 // NewAPIClient prepares a client for use with the Customer.io API, see: https://customer.io/docs/api/#apicoreintroduction
 // using an App API Key from https://fly.customer.io/settings/api_credentials?keyType=app
+//
+// NewAPIClient panics if any option is invalid; use NewAPIClientE when
+// options come from runtime configuration.
 func NewAPIClient(key string, opts ...Option) *APIClient {
+	if checked := checkOptions(opts); len(checked.errs) > 0 {
+		panic(checked.errs[0].Error())
+	} else if checked.autoRegion {
+		panic("customerio: WithAutoRegion requires NewAPIClientCtx or NewAPIClientE")
+	}
+	return newAPIClient(key, opts)
+}
+
+// NewAPIClientE is like NewAPIClient but returns an error instead of
+// panicking. It also requires a non-empty key and an https base URL, unless
+// WithInsecureURL is given. All problems are reported together.
+func NewAPIClientE(key string, opts ...Option) (*APIClient, error) {
+	return NewAPIClientCtx(context.Background(), key, opts...)
+}
+
+// NewAPIClientCtx is like NewAPIClientE but uses ctx to detect the region for
+// WithAutoRegion.
+func NewAPIClientCtx(ctx context.Context, key string, opts ...Option) (*APIClient, error) {
+	checked := checkOptions(opts)
+	errs := checked.errs
+	if key == "" && !checked.credentials {
+		errs = append(errs, ParamError{Param: "key"})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	opts, err := detectRegions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	client := newAPIClient(key, opts)
+	if err := checkBaseURL(client.URL, checked.insecure); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func newAPIClient(key string, opts []Option) *APIClient {
 	client := &APIClient{
 		Key:       key,
 		Client:    newDefaultHTTPClient(),
@@ -39,7 +89,39 @@ func NewAPIClient(key string, opts ...Option) *APIClient {
 }
 
 func (c *APIClient) doRequest(ctx context.Context, verb, requestPath string, body any) ([]byte, int, error) {
-	return doHTTP(ctx, c.Client, verb, c.URL+requestPath, c.UserAgent, body, func(req *http.Request) {
-		req.Header.Set("Authorization", "Bearer "+c.Key)
+	return doAuthenticated(ctx, c.creds, Credentials{AppAPIKey: c.Key}, func(creds Credentials) ([]byte, int, error) {
+		return doHTTP(ctx, c.Client, verb, c.URL+requestPath, c.UserAgent, body, func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+creds.AppAPIKey)
+		})
 	})
 }
+
+// ErrEmptyResponse is returned when Customer.io answers a request whose
+// result is needed, such as the ID of a triggered broadcast, with no body.
+var ErrEmptyResponse = errors.New("customerio: empty response body")
+
+// requestJSON performs an App API request and decodes a successful JSON
+// response into out, which may be nil when the response body is not needed.
+// When out is not nil an empty response is an error.
+func (c *APIClient) requestJSON(ctx context.Context, verb, requestPath string, body, out any) error {
+	respBody, statusCode, err := c.doRequest(ctx, verb, requestPath, body)
+	if err != nil {
+		return err
+	}
+
+	if statusCode < 200 || statusCode > 299 {
+		return &CustomerIOError{
+			status: statusCode,
+			url:    c.URL + requestPath,
+			body:   respBody,
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if len(bytes.TrimSpace(respBody)) == 0 {
+		return fmt.Errorf("%w: %d from %s", ErrEmptyResponse, statusCode, c.URL+requestPath)
+	}
+	return json.Unmarshal(respBody, out)
+}
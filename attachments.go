@@ -0,0 +1,249 @@
+package customerio
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MaxAttachmentsSize is the largest total size, before encoding, of all the
+// attachments of a transactional email that Customer.io accepts.
+const MaxAttachmentsSize = 2 << 20
+
+var (
+	// ErrAttachmentsTooLarge is returned when an attachment would take the
+	// total size of a request's attachments past MaxAttachmentsSize.
+	ErrAttachmentsTooLarge = errors.New("attachments exceed the maximum total size")
+	// ErrAttachmentBlocked is returned for attachments whose file type
+	// Customer.io does not accept.
+	ErrAttachmentBlocked = errors.New("attachment file type is not allowed")
+	// ErrAttachmentChanged is returned when a file attached with AttachFile
+	// or AttachFS has changed size by the time the email is sent.
+	ErrAttachmentChanged = errors.New("attachment changed size after it was attached")
+)
+
+// blockedExtensions lists the attachment file extensions Customer.io rejects.
+var blockedExtensions = map[string]bool{}
+
+func init() {
+	for _, ext := range strings.Fields(`
+		adp app asp bas bat cer chm cmd com cpl crt csh der exe fxp gadget hlp
+		hta inf ins isp its js jse ksh lib lnk mad maf mag mam maq mar mas mat
+		mau mav maw mda mdb mde mdt mdw mdz msc msh msh1 msh2 mshxml msh1xml
+		msh2xml msi msp mst ops pcd pif plg prf prg ps1 ps1xml ps2 ps2xml psc1
+		psc2 reg scf scr sct shb shs sys tmp url vb vbe vbs vps vsmacros vss
+		vst vsw vxd ws wsc wsf wsh xnk`) {
+		blockedExtensions[ext] = true
+	}
+}
+
+// attachmentFile is an attachment that is read from its source only while
+// the request is being sent.
+type attachmentFile struct {
+	name string
+	size int64
+	open func() (io.ReadCloser, error)
+}
+
+func checkAttachmentName(name string) error {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+	if blockedExtensions[ext] {
+		return fmt.Errorf("%w: %s", ErrAttachmentBlocked, name)
+	}
+	return nil
+}
+
+// attachmentsSize returns the total decoded size of every attachment.
+func (e *SendEmailRequest) attachmentsSize() int64 {
+	var total int64
+	for _, encoded := range e.Attachments {
+		padding := strings.Count(encoded[max(len(encoded)-2, 0):], "=")
+		total += int64(base64.StdEncoding.DecodedLen(len(encoded)) - padding)
+	}
+	for _, f := range e.files {
+		total += f.size
+	}
+	return total
+}
+
+func (e *SendEmailRequest) hasAttachment(name string) bool {
+	if _, ok := e.Attachments[name]; ok {
+		return true
+	}
+	for _, f := range e.files {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAttachment reports whether an attachment called name of size bytes
+// can be added to the request.
+func (e *SendEmailRequest) checkAttachment(name string, size int64) error {
+	if e.hasAttachment(name) {
+		return ErrAttachmentExists
+	}
+	if err := checkAttachmentName(name); err != nil {
+		return err
+	}
+	if total := e.attachmentsSize() + size; total > MaxAttachmentsSize {
+		return fmt.Errorf("%w: adding %s makes %d bytes, limit is %d", ErrAttachmentsTooLarge, name, total, MaxAttachmentsSize)
+	}
+	return nil
+}
+
+// AttachFile attaches the file at filePath, named after its base name. The
+// file is not read until the email is sent, when it is streamed into the
+// request body.
+func (e *SendEmailRequest) AttachFile(filePath string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("attachment %s is not a regular file", filePath)
+	}
+	name := filepath.Base(filePath)
+	if err := e.checkAttachment(name, info.Size()); err != nil {
+		return err
+	}
+	e.files = append(e.files, attachmentFile{
+		name: name,
+		size: info.Size(),
+		open: func() (io.ReadCloser, error) { return os.Open(filePath) },
+	})
+	return nil
+}
+
+// AttachFS attaches the named file from fsys, named after its base name. Like
+// AttachFile, the file is streamed when the email is sent.
+func (e *SendEmailRequest) AttachFS(fsys fs.FS, name string) error {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("attachment %s is not a regular file", name)
+	}
+	base := path.Base(name)
+	if err := e.checkAttachment(base, info.Size()); err != nil {
+		return err
+	}
+	e.files = append(e.files, attachmentFile{
+		name: base,
+		size: info.Size(),
+		open: func() (io.ReadCloser, error) { return fsys.Open(name) },
+	})
+	return nil
+}
+
+// streamJSON returns a function that writes the request as JSON, encoding
+// file attachments directly from their source, and the number of bytes it
+// writes. It returns a nil function if the request has no file attachments
+// and can be marshaled normally.
+func (e *SendEmailRequest) streamJSON() (func(io.Writer) error, int64, error) {
+	if len(e.files) == 0 {
+		return nil, 0, nil
+	}
+
+	// Everything but the file contents is encoded up front, so the length
+	// of the body is known before it is sent.
+	rest := *e
+	rest.Attachments = nil
+	b, err := json.Marshal(&rest)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Reopen the object to append the attachments; it always has at least
+	// the identifiers field.
+	head := bytes.NewBuffer(b[:len(b)-1])
+	head.WriteString(`,"attachments":{`)
+	first := true
+	writeKey := func(buf *bytes.Buffer, name string) error {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		if !first {
+			buf.WriteString(",")
+		}
+		first = false
+		buf.Write(key)
+		buf.WriteString(":")
+		return nil
+	}
+	for name, encoded := range e.Attachments {
+		if err := writeKey(head, name); err != nil {
+			return nil, 0, err
+		}
+		value, err := json.Marshal(encoded)
+		if err != nil {
+			return nil, 0, err
+		}
+		head.Write(value)
+	}
+	keys := make([][]byte, len(e.files))
+	size := int64(head.Len()) + int64(len("}}"))
+	for i, f := range e.files {
+		var key bytes.Buffer
+		if err := writeKey(&key, f.name); err != nil {
+			return nil, 0, err
+		}
+		keys[i] = key.Bytes()
+		size += int64(len(keys[i])) + int64(len(`""`)) + int64(base64.StdEncoding.EncodedLen(int(f.size)))
+	}
+	files := slices.Clone(e.files)
+
+	return func(w io.Writer) error {
+		if _, err := w.Write(head.Bytes()); err != nil {
+			return err
+		}
+		for i, f := range files {
+			if _, err := w.Write(keys[i]); err != nil {
+				return err
+			}
+			if err := f.writeBase64(w); err != nil {
+				return err
+			}
+		}
+		_, err := io.WriteString(w, "}}")
+		return err
+	}, size, nil
+}
+
+// writeBase64 writes the file's contents to w as a base64 JSON string. The
+// file must still be the size it was when it was attached.
+func (f attachmentFile) writeBase64(w io.Writer) error {
+	r, err := f.open()
+	if err != nil {
+		return fmt.Errorf("attachment %s: %w", f.name, err)
+	}
+	defer func() { _ = r.Close() }()
+
+	if _, err := io.WriteString(w, `"`); err != nil {
+		return err
+	}
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	n, err := io.Copy(enc, io.LimitReader(r, f.size))
+	if err != nil {
+		return fmt.Errorf("attachment %s: %w", f.name, err)
+	}
+	if _, err := io.ReadFull(r, make([]byte, 1)); n < f.size || err == nil {
+		return fmt.Errorf("%w: %s was %d bytes when attached", ErrAttachmentChanged, f.name, f.size)
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, `"`)
+	return err
+}
@@ -0,0 +1,171 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// BroadcastTrigger describes the processing state of a single broadcast trigger.
+type BroadcastTrigger struct {
+	ID          int `json:"id"`
+	BroadcastID int `json:"campaign_id"`
+	// Processed reports whether Customer.io has finished processing the trigger.
+	Processed bool `json:"processed"`
+	// Status is the server-reported trigger state, e.g. "pending" or "complete".
+	Status string `json:"status"`
+	// ProcessedCount is the number of recipients processed so far.
+	ProcessedCount int `json:"processed_count"`
+	// ErrorCount is the number of recipients that could not be processed.
+	// Use ListBroadcastTriggerErrors to retrieve them.
+	ErrorCount  int       `json:"error_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+func (t *BroadcastTrigger) UnmarshalJSON(b []byte) error {
+	type trigger BroadcastTrigger
+	var r struct {
+		*trigger
+		CreatedAt   int64 `json:"created_at"`
+		ProcessedAt int64 `json:"processed_at"`
+	}
+	r.trigger = (*trigger)(t)
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	t.CreatedAt = unixTime(r.CreatedAt)
+	t.ProcessedAt = unixTime(r.ProcessedAt)
+	return nil
+}
+
+// BroadcastTriggerError describes a recipient that failed during broadcast processing.
+type BroadcastTriggerError struct {
+	// BatchNum is the zero-based batch of recipients the error occurred in.
+	BatchNum int `json:"batch_num"`
+	// Reason is a short machine-readable reason, e.g. "missing_customer".
+	Reason string `json:"reason"`
+	// Field is the recipient field that caused the error, if any.
+	Field string `json:"field"`
+	// Message is a human-readable description of the error.
+	Message string `json:"message"`
+}
+
+// BroadcastTriggerErrors is a page of per-recipient broadcast trigger errors.
+type BroadcastTriggerErrors struct {
+	Errors []BroadcastTriggerError `json:"errors"`
+	// Next is the cursor for the following page, empty on the last page.
+	Next string `json:"next"`
+}
+
+// GetBroadcastTrigger returns the processing status of a broadcast trigger,
+// identified by the BroadcastResponse.ID returned from TriggerBroadcast.
+// See https://docs.customer.io/api/app/#operation/getBroadcastTriggerStatus
+func (c *APIClient) GetBroadcastTrigger(ctx context.Context, broadcastID, triggerID int) (*BroadcastTrigger, error) {
+	if broadcastID <= 0 {
+		return nil, ParamError{Param: "broadcastID"}
+	}
+	if triggerID <= 0 {
+		return nil, ParamError{Param: "triggerID"}
+	}
+
+	var trigger BroadcastTrigger
+	if err := c.requestJSON(ctx, "GET", formatPath("/v1/campaigns/%d/triggers/%d", broadcastID, triggerID), nil, &trigger); err != nil {
+		return nil, err
+	}
+	return &trigger, nil
+}
+
+// ListBroadcastTriggerErrors returns a page of per-recipient errors for a broadcast trigger.
+// See https://docs.customer.io/api/app/#operation/getBroadcastTriggerErrors
+func (c *APIClient) ListBroadcastTriggerErrors(ctx context.Context, broadcastID, triggerID int, page PageOptions) (*BroadcastTriggerErrors, error) {
+	if broadcastID <= 0 {
+		return nil, ParamError{Param: "broadcastID"}
+	}
+	if triggerID <= 0 {
+		return nil, ParamError{Param: "triggerID"}
+	}
+
+	var errs BroadcastTriggerErrors
+	requestPath := withQuery(formatPath("/v1/campaigns/%d/triggers/%d/errors", broadcastID, triggerID), page.values())
+	if err := c.requestJSON(ctx, "GET", requestPath, nil, &errs); err != nil {
+		return nil, err
+	}
+	return &errs, nil
+}
+
+// WaitForBroadcastTrigger polls GetBroadcastTrigger with exponential backoff
+// until the trigger has been processed or ctx is done. Rate limiting, server
+// and network errors are retried; any other error ends the wait. It returns
+// the last trigger status received, with the error that ended the wait or
+// ctx.Err().
+func (c *APIClient) WaitForBroadcastTrigger(ctx context.Context, broadcastID, triggerID int) (*BroadcastTrigger, error) {
+	var trigger *BroadcastTrigger
+	err := poll(ctx, func() (bool, error) {
+		t, err := c.GetBroadcastTrigger(ctx, broadcastID, triggerID)
+		if err != nil {
+			return false, err
+		}
+		trigger = t
+		return t.Processed, nil
+	})
+	return trigger, err
+}
+
+const (
+	// pollInterval is the delay before the second check made by poll; it
+	// doubles after every check up to pollMaxInterval.
+	pollInterval    = 500 * time.Millisecond
+	pollMaxInterval = 30 * time.Second
+)
+
+// poll calls check with exponential backoff until it reports done, returns an
+// error that is not worth retrying, or ctx is done.
+func poll(ctx context.Context, check func() (bool, error)) error {
+	interval := pollInterval
+	for {
+		done, err := check()
+		if err != nil && !retryable(err) {
+			return err
+		}
+		if err == nil && done {
+			return nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > pollMaxInterval {
+			interval = pollMaxInterval
+		}
+	}
+}
+
+// retryable reports whether err is a rate limiting, server or network error,
+// which may not happen again if the request is repeated.
+func retryable(err error) bool {
+	var apiErr *CustomerIOError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode() == http.StatusTooManyRequests || apiErr.StatusCode() >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// unixTime converts a Unix timestamp in seconds to a time.Time, mapping 0 to
+// the zero time so unset timestamps report IsZero.
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
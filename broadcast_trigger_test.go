@@ -0,0 +1,165 @@
+package customerio_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestGetBroadcastTrigger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "GET" || req.URL.Path != "/v1/campaigns/12/triggers/34" {
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer myKey" {
+			t.Errorf("unexpected Authorization %q", got)
+		}
+		_, _ = w.Write([]byte(`{"id":34,"campaign_id":12,"processed":true,"status":"complete","processed_count":10,"error_count":2,"created_at":1500000000,"processed_at":1500000060}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	trigger, err := api.GetBroadcastTrigger(context.Background(), 12, 34)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &customerio.BroadcastTrigger{
+		ID:             34,
+		BroadcastID:    12,
+		Processed:      true,
+		Status:         "complete",
+		ProcessedCount: 10,
+		ErrorCount:     2,
+		CreatedAt:      time.Unix(1500000000, 0),
+		ProcessedAt:    time.Unix(1500000060, 0),
+	}
+	if !reflect.DeepEqual(trigger, want) {
+		t.Errorf("want %#v got %#v", want, trigger)
+	}
+
+	_, err = api.GetBroadcastTrigger(context.Background(), 0, 34)
+	checkParamError(t, err, "broadcastID")
+	_, err = api.GetBroadcastTrigger(context.Background(), 12, 0)
+	checkParamError(t, err, "triggerID")
+}
+
+func TestListBroadcastTriggerErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/campaigns/12/triggers/34/errors" {
+			t.Errorf("unexpected path %s", req.URL.Path)
+		}
+		if got := req.URL.RawQuery; got != "limit=2&start=abc" {
+			t.Errorf("unexpected query %q", got)
+		}
+		_, _ = w.Write([]byte(`{"errors":[{"batch_num":0,"reason":"missing_customer","field":"id","message":"customer 7 not found"}],"next":"def"}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	page, err := api.ListBroadcastTriggerErrors(context.Background(), 12, 34, customerio.PageOptions{Start: "abc", Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &customerio.BroadcastTriggerErrors{
+		Errors: []customerio.BroadcastTriggerError{
+			{BatchNum: 0, Reason: "missing_customer", Field: "id", Message: "customer 7 not found"},
+		},
+		Next: "def",
+	}
+	if !reflect.DeepEqual(page, want) {
+		t.Errorf("want %#v got %#v", want, page)
+	}
+}
+
+func TestWaitForBroadcastTrigger(t *testing.T) {
+	var polls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		polls++
+		if polls < 2 {
+			_, _ = w.Write([]byte(`{"id":34,"processed":false}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":34,"processed":true}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	trigger, err := api.WaitForBroadcastTrigger(context.Background(), 12, 34)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trigger.Processed || polls != 2 {
+		t.Errorf("expected processed trigger after 2 polls, got %#v after %d", trigger, polls)
+	}
+}
+
+func TestWaitForBroadcastTriggerRetries(t *testing.T) {
+	var polls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		polls++
+		if polls < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":34,"processed":true}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	trigger, err := api.WaitForBroadcastTrigger(context.Background(), 12, 34)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trigger.Processed || polls != 2 {
+		t.Errorf("expected processed trigger after 2 polls, got %#v after %d", trigger, polls)
+	}
+}
+
+func TestWaitForBroadcastTriggerStopsOnClientError(t *testing.T) {
+	var polls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		polls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	_, err := api.WaitForBroadcastTrigger(context.Background(), 12, 34)
+	var apiErr *customerio.CustomerIOError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode() != http.StatusNotFound || polls != 1 {
+		t.Errorf("expected a 404 after one poll, got %v after %d", err, polls)
+	}
+}
+
+func TestWaitForBroadcastTriggerContextDone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(`{"id":34,"processed":false}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	trigger, err := api.WaitForBroadcastTrigger(ctx, 12, 34)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if trigger == nil || trigger.Processed {
+		t.Errorf("expected last unprocessed status, got %#v", trigger)
+	}
+}
@@ -0,0 +1,87 @@
+package customerio
+
+import (
+	"context"
+	"time"
+)
+
+// Broadcast describes a Customer.io broadcast.
+type Broadcast struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	State  string   `json:"state"`
+	Active bool     `json:"active"`
+	Tags   []string `json:"tags"`
+	// Actions lists the IDs of the broadcast's actions; use ListBroadcastActions
+	// for their details.
+	Actions      []int     `json:"-"`
+	CreatedAt    time.Time `json:"created"`
+	UpdatedAt    time.Time `json:"updated"`
+	FirstStarted time.Time `json:"first_started"`
+}
+
+func (bc *Broadcast) UnmarshalJSON(b []byte) error {
+	return (*workflow)(bc).UnmarshalJSON(b)
+}
+
+// BroadcastList is a page of broadcasts.
+type BroadcastList struct {
+	Broadcasts []Broadcast `json:"broadcasts"`
+	// Next is the cursor for the following page, empty on the last page.
+	Next string `json:"next"`
+}
+
+// ListBroadcasts returns a page of the workspace's broadcasts.
+// See https://docs.customer.io/api/app/#operation/listBroadcasts
+func (c *APIClient) ListBroadcasts(ctx context.Context, page PageOptions) (*BroadcastList, error) {
+	var list BroadcastList
+	if err := c.requestJSON(ctx, "GET", withQuery("/v1/broadcasts", page.values()), nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetBroadcast returns a single broadcast.
+// See https://docs.customer.io/api/app/#operation/getBroadcast
+func (c *APIClient) GetBroadcast(ctx context.Context, broadcastID int) (*Broadcast, error) {
+	if broadcastID <= 0 {
+		return nil, ParamError{Param: "broadcastID"}
+	}
+
+	var resp struct {
+		Broadcast Broadcast `json:"broadcast"`
+	}
+	if err := c.requestJSON(ctx, "GET", formatPath("/v1/broadcasts/%d", broadcastID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Broadcast, nil
+}
+
+// ListBroadcastActions returns a page of a broadcast's actions.
+// See https://docs.customer.io/api/app/#operation/listBroadcastActions
+func (c *APIClient) ListBroadcastActions(ctx context.Context, broadcastID int, page PageOptions) (*ActionList, error) {
+	if broadcastID <= 0 {
+		return nil, ParamError{Param: "broadcastID"}
+	}
+
+	var list ActionList
+	if err := c.requestJSON(ctx, "GET", withQuery(formatPath("/v1/broadcasts/%d/actions", broadcastID), page.values()), nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetBroadcastMetrics returns a broadcast's message metrics over the queried time range.
+// See https://docs.customer.io/api/app/#operation/broadcastMetrics
+func (c *APIClient) GetBroadcastMetrics(ctx context.Context, broadcastID int, query MetricsQuery) (*Metrics, error) {
+	if broadcastID <= 0 {
+		return nil, ParamError{Param: "broadcastID"}
+	}
+
+	var resp metricsResponse
+	if err := c.requestJSON(ctx, "GET", withQuery(formatPath("/v1/broadcasts/%d/metrics", broadcastID), query.values()), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Metric.Series, nil
+}
@@ -0,0 +1,75 @@
+package customerio_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestListBroadcasts(t *testing.T) {
+	api := appServer(t, "GET", "/v1/broadcasts", `{"broadcasts":[{"id":3,"name":"Launch","active":true,"actions":[{"id":7}],"first_started":1500000000}]}`)
+
+	list, err := api.ListBroadcasts(context.Background(), customerio.PageOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &customerio.BroadcastList{
+		Broadcasts: []customerio.Broadcast{{
+			ID:           3,
+			Name:         "Launch",
+			Active:       true,
+			Actions:      []int{7},
+			FirstStarted: time.Unix(1500000000, 0),
+		}},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("want %#v got %#v", want, list)
+	}
+}
+
+func TestGetBroadcast(t *testing.T) {
+	api := appServer(t, "GET", "/v1/broadcasts/3", `{"broadcast":{"id":3,"name":"Launch"}}`)
+
+	broadcast, err := api.GetBroadcast(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if broadcast.ID != 3 || broadcast.Name != "Launch" {
+		t.Errorf("unexpected broadcast %#v", broadcast)
+	}
+
+	_, err = api.GetBroadcast(context.Background(), -1)
+	checkParamError(t, err, "broadcastID")
+}
+
+func TestListBroadcastActions(t *testing.T) {
+	api := appServer(t, "GET", "/v1/broadcasts/3/actions", `{"actions":[{"id":7,"broadcast_id":3,"type":"push"}],"next":"n"}`)
+
+	list, err := api.ListBroadcastActions(context.Background(), 3, customerio.PageOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &customerio.ActionList{
+		Actions: []customerio.Action{{ID: 7, BroadcastID: 3, Type: "push"}},
+		Next:    "n",
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("want %#v got %#v", want, list)
+	}
+}
+
+func TestGetBroadcastMetrics(t *testing.T) {
+	api := appServer(t, "GET", "/v1/broadcasts/3/metrics?res=weeks", `{"metric":{"series":{"sent":[10],"opened":[4]}}}`)
+
+	metrics, err := api.GetBroadcastMetrics(context.Background(), 3, customerio.MetricsQuery{Period: customerio.MetricsPeriodWeeks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(metrics.Sent, []int{10}) || !reflect.DeepEqual(metrics.Opened, []int{4}) {
+		t.Errorf("unexpected metrics %#v", metrics)
+	}
+}
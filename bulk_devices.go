@@ -0,0 +1,213 @@
+package customerio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultDeviceConcurrency is the number of device requests the bulk device
+// methods run at once unless WithDeviceConcurrency is given.
+const DefaultDeviceConcurrency = 8
+
+// DeviceRegistration is one device to add with AddDevices.
+type DeviceRegistration struct {
+	CustomerID string
+	Token      string
+	Platform   Platform
+	// Data holds last_used and the device attributes, as for AddDeviceCtx.
+	Data map[string]any
+}
+
+// DeviceRef identifies a customer's device to delete with DeleteDevices.
+type DeviceRef struct {
+	CustomerID string
+	Token      string
+}
+
+// DeviceMove moves a device token from one customer to another with
+// MoveDevices.
+type DeviceMove struct {
+	Token    string
+	Platform Platform
+	From     string
+	To       string
+	// Data holds last_used and the device attributes to register the device
+	// with on the new customer.
+	Data map[string]any
+}
+
+// DeviceResult is the outcome of one item of a bulk device call.
+type DeviceResult struct {
+	// CustomerID is the customer the device was added to or deleted from;
+	// for a move it is the customer the device was moved to.
+	CustomerID string
+	Token      string
+	// Duplicate reports that the item was skipped because a later item in
+	// the same call has the same token, or for deletes the same customer and
+	// token.
+	Duplicate bool
+	Err       error
+}
+
+// DeviceResults holds the outcome of every item of a bulk device call, in
+// the order the items were given.
+type DeviceResults []DeviceResult
+
+// Failed returns the results of the items that failed.
+func (rs DeviceResults) Failed() DeviceResults {
+	var failed DeviceResults
+	for _, res := range rs {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Err returns the errors of every failed item joined together, each prefixed
+// with its customer and token, or nil if all succeeded.
+func (rs DeviceResults) Err() error {
+	var errs []error
+	for _, res := range rs.Failed() {
+		errs = append(errs, fmt.Errorf("customer %s device %s: %w", res.CustomerID, res.Token, res.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// BulkDeviceOption configures a bulk device call.
+type BulkDeviceOption func(*bulkDevices)
+
+// WithDeviceConcurrency sets the number of device requests run at once.
+// Values below 1 are ignored.
+func WithDeviceConcurrency(n int) BulkDeviceOption {
+	return func(b *bulkDevices) {
+		if n > 0 {
+			b.concurrency = n
+		}
+	}
+}
+
+type bulkDevices struct {
+	concurrency int
+}
+
+// runBulkDevices calls fn for every item whose result is not a duplicate, with at most
+// the configured number of calls in flight. Items that have not started when
+// ctx is done fail with the context's error.
+func runBulkDevices(ctx context.Context, results DeviceResults, opts []BulkDeviceOption, fn func(ctx context.Context, i int) error) DeviceResults {
+	b := bulkDevices{concurrency: DefaultDeviceConcurrency}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&b)
+		}
+	}
+
+	sem := make(chan struct{}, b.concurrency)
+	var wg sync.WaitGroup
+	for i := range results {
+		if results[i].Duplicate {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i].Err = fn(ctx, i)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// markDuplicates flags every result whose key appears again later.
+func markDuplicates(results DeviceResults, key func(DeviceResult) string) {
+	seen := make(map[string]bool, len(results))
+	for i := len(results) - 1; i >= 0; i-- {
+		k := key(results[i])
+		results[i].Duplicate = seen[k]
+		seen[k] = true
+	}
+}
+
+// AddDevices adds many devices concurrently, reporting the result of each.
+// A token given more than once is only registered by its last item, so a
+// token always ends up with the last customer and data given for it.
+func (c *CustomerIO) AddDevices(ctx context.Context, devices []DeviceRegistration, opts ...BulkDeviceOption) DeviceResults {
+	results := make(DeviceResults, len(devices))
+	for i, d := range devices {
+		results[i] = DeviceResult{CustomerID: d.CustomerID, Token: d.Token}
+	}
+	markDuplicates(results, func(r DeviceResult) string { return r.Token })
+	return runBulkDevices(ctx, results, opts, func(ctx context.Context, i int) error {
+		d := devices[i]
+		return c.AddDeviceCtx(ctx, d.CustomerID, d.Token, string(d.Platform), d.Data)
+	})
+}
+
+// DeleteDevices deletes many devices concurrently, reporting the result of
+// each. Repeated customer and token pairs are only deleted once.
+func (c *CustomerIO) DeleteDevices(ctx context.Context, devices []DeviceRef, opts ...BulkDeviceOption) DeviceResults {
+	results := make(DeviceResults, len(devices))
+	for i, d := range devices {
+		results[i] = DeviceResult{CustomerID: d.CustomerID, Token: d.Token}
+	}
+	markDuplicates(results, func(r DeviceResult) string { return r.CustomerID + "\x00" + r.Token })
+	return runBulkDevices(ctx, results, opts, func(ctx context.Context, i int) error {
+		d := devices[i]
+		return c.DeleteDeviceCtx(ctx, d.CustomerID, d.Token)
+	})
+}
+
+// MoveDevices moves many device tokens between customers concurrently,
+// reporting the result of each. Each token is deleted from its old customer
+// before it is added to the new one, since the Track API may resolve the
+// delete by token alone and would otherwise remove the new registration. If
+// the delete fails the token stays with the old customer; if only the add
+// fails the token is left unregistered and the error says so. A token given
+// more than once is only moved by its last item.
+func (c *CustomerIO) MoveDevices(ctx context.Context, moves []DeviceMove, opts ...BulkDeviceOption) DeviceResults {
+	results := make(DeviceResults, len(moves))
+	for i, m := range moves {
+		results[i] = DeviceResult{CustomerID: m.To, Token: m.Token}
+	}
+	markDuplicates(results, func(r DeviceResult) string { return r.Token })
+	return runBulkDevices(ctx, results, opts, func(ctx context.Context, i int) error {
+		return c.moveDevice(ctx, moves[i])
+	})
+}
+
+func (c *CustomerIO) moveDevice(ctx context.Context, m DeviceMove) error {
+	if m.From == "" {
+		return ParamError{Param: "from"}
+	}
+	if m.To == "" {
+		return ParamError{Param: "to"}
+	}
+	// Check the device before deleting anything, so an invalid move leaves
+	// the token where it was.
+	if _, err := newDeviceV1(m.Token, string(m.Platform), m.Data); err != nil {
+		return err
+	}
+	if m.From != m.To {
+		if err := c.DeleteDeviceCtx(ctx, m.From, m.Token); err != nil {
+			return fmt.Errorf("not deleted from %s: %w", m.From, err)
+		}
+	}
+	if err := c.AddDeviceCtx(ctx, m.To, m.Token, string(m.Platform), m.Data); err != nil {
+		if m.From == m.To {
+			return err
+		}
+		return fmt.Errorf("deleted from %s but not added to %s: %w", m.From, m.To, err)
+	}
+	return nil
+}
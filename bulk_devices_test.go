@@ -0,0 +1,229 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+// deviceServer records the method and path of every request, failing those
+// whose path contains "fail".
+func deviceServer(t *testing.T) (*customerio.CustomerIO, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		requests = append(requests, req.Method+" "+req.URL.EscapedPath())
+		mu.Unlock()
+		if strings.Contains(req.URL.Path, "fail") {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return customerio.NewTrackClient("siteid", "apikey", customerio.WithURL(srv.URL)), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		out := slices.Clone(requests)
+		slices.Sort(out)
+		return out
+	}
+}
+
+func TestAddDevices(t *testing.T) {
+	client, requests := deviceServer(t)
+
+	results := client.AddDevices(context.Background(), []customerio.DeviceRegistration{
+		{CustomerID: "1", Token: apnsToken, Platform: customerio.PlatformIOS},
+		{CustomerID: "2", Token: fcmToken, Platform: customerio.PlatformAndroid},
+		{CustomerID: "3", Token: apnsToken, Platform: customerio.PlatformIOS},
+		{CustomerID: "fail", Token: "tok-1", Platform: customerio.PlatformAndroid},
+		{CustomerID: "4", Token: "tok-2", Platform: "web"},
+	})
+
+	if want := []string{"PUT /api/v1/customers/2/devices", "PUT /api/v1/customers/3/devices", "PUT /api/v1/customers/fail/devices"}; !slices.Equal(requests(), want) {
+		t.Errorf("requests: got %v, want %v", requests(), want)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected 5 results, got %d", len(results))
+	}
+	if !results[0].Duplicate || results[0].Err != nil {
+		t.Errorf("expected the first registration of a repeated token to be skipped, got %+v", results[0])
+	}
+	if results[1].Err != nil || results[1].Duplicate {
+		t.Errorf("unexpected result %+v", results[1])
+	}
+	if results[2].Duplicate || results[2].Err != nil {
+		t.Errorf("expected the last registration of a repeated token to be kept, got %+v", results[2])
+	}
+	if results[3].Err == nil {
+		t.Error("expected a server error for the failing customer")
+	}
+	if !errors.Is(results[4].Err, customerio.ErrUnknownPlatform) {
+		t.Errorf("expected ErrUnknownPlatform, got %v", results[4].Err)
+	}
+	if n := len(results.Failed()); n != 2 {
+		t.Errorf("expected 2 failures, got %d", n)
+	}
+	if err := results.Err(); err == nil || !strings.Contains(err.Error(), "customer fail device tok-1") {
+		t.Errorf("unexpected joined error %v", err)
+	}
+}
+
+func TestDeleteDevices(t *testing.T) {
+	client, requests := deviceServer(t)
+
+	results := client.DeleteDevices(context.Background(), []customerio.DeviceRef{
+		{CustomerID: "1", Token: "a"},
+		{CustomerID: "1", Token: "a"},
+		{CustomerID: "2", Token: "a"},
+		{CustomerID: "", Token: "b"},
+	})
+
+	if want := []string{"DELETE /api/v1/customers/1/devices/a", "DELETE /api/v1/customers/2/devices/a"}; !slices.Equal(requests(), want) {
+		t.Errorf("requests: got %v, want %v", requests(), want)
+	}
+	if !results[0].Duplicate || results[1].Duplicate || results[2].Duplicate {
+		t.Errorf("unexpected duplicates %+v", results)
+	}
+	checkParamError(t, results[3].Err, "customerID")
+}
+
+func TestMoveDevices(t *testing.T) {
+	client, requests := deviceServer(t)
+
+	results := client.MoveDevices(context.Background(), []customerio.DeviceMove{
+		{Token: apnsToken, Platform: customerio.PlatformIOS, From: "1", To: "2"},
+		{Token: fcmToken, Platform: customerio.PlatformAndroid, From: "fail", To: "3"},
+		{Token: "tok-1", Platform: customerio.PlatformAndroid, From: "4", To: "fail"},
+		{Token: "tok-2", Platform: customerio.PlatformAndroid, To: "5"},
+	})
+
+	want := []string{
+		"DELETE /api/v1/customers/1/devices/" + apnsToken,
+		"DELETE /api/v1/customers/4/devices/tok-1",
+		"DELETE /api/v1/customers/fail/devices/" + fcmToken,
+		"PUT /api/v1/customers/2/devices",
+		"PUT /api/v1/customers/fail/devices",
+	}
+	if !slices.Equal(requests(), want) {
+		t.Errorf("requests: got %v, want %v", requests(), want)
+	}
+	if results[0].Err != nil || results[0].CustomerID != "2" {
+		t.Errorf("unexpected result %+v", results[0])
+	}
+	if results[1].Err == nil || !strings.Contains(results[1].Err.Error(), "not deleted from fail") {
+		t.Errorf("expected a failed delete to be reported, got %v", results[1].Err)
+	}
+	if results[2].Err == nil || !strings.Contains(results[2].Err.Error(), "deleted from 4 but not added to fail") {
+		t.Errorf("expected a failed add to be reported, got %v", results[2].Err)
+	}
+	checkParamError(t, results[3].Err, "from")
+}
+
+func TestMoveDevicesRegistersToNewCustomer(t *testing.T) {
+	// The server keys devices by token alone, so deleting a token removes
+	// it whichever customer it is registered to.
+	var mu sync.Mutex
+	owners := map[string]string{apnsToken: "1"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		parts := strings.Split(req.URL.Path, "/")
+		switch req.Method {
+		case "PUT":
+			var body struct {
+				Device struct {
+					ID string `json:"id"`
+				} `json:"device"`
+			}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+			owners[body.Device.ID] = parts[4]
+		case "DELETE":
+			delete(owners, parts[6])
+		}
+	}))
+	defer srv.Close()
+	client := customerio.NewTrackClient("siteid", "apikey", customerio.WithURL(srv.URL))
+
+	results := client.MoveDevices(context.Background(), []customerio.DeviceMove{
+		{Token: apnsToken, Platform: customerio.PlatformIOS, From: "1", To: "2"},
+	})
+	if err := results.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if owners[apnsToken] != "2" {
+		t.Errorf("token registered to %q, want 2", owners[apnsToken])
+	}
+}
+
+func TestMoveDevicesInvalidDeviceDeletesNothing(t *testing.T) {
+	client, requests := deviceServer(t)
+
+	results := client.MoveDevices(context.Background(), []customerio.DeviceMove{
+		{Token: "bad token", Platform: customerio.PlatformIOS, From: "1", To: "2"},
+	})
+	if !errors.Is(results[0].Err, customerio.ErrInvalidDeviceToken) {
+		t.Errorf("expected ErrInvalidDeviceToken, got %v", results[0].Err)
+	}
+	if got := requests(); len(got) != 0 {
+		t.Errorf("unexpected requests %v", got)
+	}
+}
+
+func TestBulkDevicesConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int64
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	client := customerio.NewTrackClient("siteid", "apikey", customerio.WithURL(srv.URL))
+
+	var refs []customerio.DeviceRef
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		refs = append(refs, customerio.DeviceRef{CustomerID: "1", Token: id})
+	}
+	done := make(chan customerio.DeviceResults)
+	go func() {
+		done <- client.DeleteDevices(context.Background(), refs, customerio.WithDeviceConcurrency(2))
+	}()
+	close(release)
+	if err := (<-done).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", p)
+	}
+}
+
+func TestBulkDevicesCanceled(t *testing.T) {
+	client, _ := deviceServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := client.DeleteDevices(ctx, []customerio.DeviceRef{{CustomerID: "1", Token: "a"}}, customerio.WithDeviceConcurrency(1))
+	// The single slot is free, so the item may start; either way it fails.
+	if !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", results[0].Err)
+	}
+}
@@ -0,0 +1,222 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Campaign describes a Customer.io campaign.
+type Campaign struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	State  string   `json:"state"`
+	Active bool     `json:"active"`
+	Tags   []string `json:"tags"`
+	// Actions lists the IDs of the campaign's actions; use ListCampaignActions
+	// for their details.
+	Actions      []int     `json:"-"`
+	CreatedAt    time.Time `json:"created"`
+	UpdatedAt    time.Time `json:"updated"`
+	FirstStarted time.Time `json:"first_started"`
+}
+
+func (c *Campaign) UnmarshalJSON(b []byte) error {
+	return (*workflow)(c).UnmarshalJSON(b)
+}
+
+// workflow has the fields Campaign and Broadcast share, so both can convert
+// to it to decode the API's form of them.
+type workflow struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	State        string    `json:"state"`
+	Active       bool      `json:"active"`
+	Tags         []string  `json:"tags"`
+	Actions      []int     `json:"-"`
+	CreatedAt    time.Time `json:"created"`
+	UpdatedAt    time.Time `json:"updated"`
+	FirstStarted time.Time `json:"first_started"`
+}
+
+func (w *workflow) UnmarshalJSON(b []byte) error {
+	type plain workflow
+	var r struct {
+		*plain
+		Actions      []struct{ ID int } `json:"actions"`
+		CreatedAt    int64              `json:"created"`
+		UpdatedAt    int64              `json:"updated"`
+		FirstStarted int64              `json:"first_started"`
+	}
+	r.plain = (*plain)(w)
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	w.Actions = nil
+	for _, a := range r.Actions {
+		w.Actions = append(w.Actions, a.ID)
+	}
+	w.CreatedAt = unixTime(r.CreatedAt)
+	w.UpdatedAt = unixTime(r.UpdatedAt)
+	w.FirstStarted = unixTime(r.FirstStarted)
+	return nil
+}
+
+// CampaignList is a page of campaigns.
+type CampaignList struct {
+	Campaigns []Campaign `json:"campaigns"`
+	// Next is the cursor for the following page, empty on the last page.
+	Next string `json:"next"`
+}
+
+// Action describes a single message or workflow step in a campaign or broadcast.
+type Action struct {
+	ID             int       `json:"id"`
+	CampaignID     int       `json:"campaign_id,omitempty"`
+	BroadcastID    int       `json:"broadcast_id,omitempty"`
+	ParentActionID int       `json:"parent_action_id,omitempty"`
+	DeduplicateID  string    `json:"deduplicate_id"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	Language       string    `json:"language"`
+	Subject        string    `json:"subject"`
+	From           string    `json:"from"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"created"`
+	UpdatedAt      time.Time `json:"updated"`
+}
+
+func (a *Action) UnmarshalJSON(b []byte) error {
+	type action Action
+	var r struct {
+		*action
+		CreatedAt int64 `json:"created"`
+		UpdatedAt int64 `json:"updated"`
+	}
+	r.action = (*action)(a)
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	a.CreatedAt = unixTime(r.CreatedAt)
+	a.UpdatedAt = unixTime(r.UpdatedAt)
+	return nil
+}
+
+// ActionList is a page of campaign or broadcast actions.
+type ActionList struct {
+	Actions []Action `json:"actions"`
+	// Next is the cursor for the following page, empty on the last page.
+	Next string `json:"next"`
+}
+
+// MetricsPeriod is the granularity of a metrics time series.
+type MetricsPeriod string
+
+const (
+	MetricsPeriodHours  MetricsPeriod = "hours"
+	MetricsPeriodDays   MetricsPeriod = "days"
+	MetricsPeriodWeeks  MetricsPeriod = "weeks"
+	MetricsPeriodMonths MetricsPeriod = "months"
+)
+
+// MetricsQuery selects the time range and granularity of a metrics request.
+// Zero values use the server defaults.
+type MetricsQuery struct {
+	Start  time.Time
+	End    time.Time
+	Period MetricsPeriod
+	// Type restricts the metrics to one message type, e.g. "email" or "push".
+	Type string
+}
+
+func (q MetricsQuery) values() url.Values {
+	v := url.Values{}
+	if !q.Start.IsZero() {
+		v.Set("start", strconv.FormatInt(q.Start.Unix(), 10))
+	}
+	if !q.End.IsZero() {
+		v.Set("end", strconv.FormatInt(q.End.Unix(), 10))
+	}
+	if q.Period != "" {
+		v.Set("res", string(q.Period))
+	}
+	if q.Type != "" {
+		v.Set("type", q.Type)
+	}
+	return v
+}
+
+// Metrics is a time series of message performance counts. Each slice holds
+// one value per period, oldest first.
+type Metrics struct {
+	Sent      []int `json:"sent"`
+	Delivered []int `json:"delivered"`
+	Opened    []int `json:"opened"`
+	Clicked   []int `json:"clicked"`
+	Converted []int `json:"converted"`
+	Bounced   []int `json:"bounced"`
+}
+
+type metricsResponse struct {
+	Metric struct {
+		Series Metrics `json:"series"`
+	} `json:"metric"`
+}
+
+// ListCampaigns returns a page of the workspace's campaigns.
+// See https://docs.customer.io/api/app/#operation/listCampaigns
+func (c *APIClient) ListCampaigns(ctx context.Context, page PageOptions) (*CampaignList, error) {
+	var list CampaignList
+	if err := c.requestJSON(ctx, "GET", withQuery("/v1/campaigns", page.values()), nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetCampaign returns a single campaign.
+// See https://docs.customer.io/api/app/#operation/getCampaign
+func (c *APIClient) GetCampaign(ctx context.Context, campaignID int) (*Campaign, error) {
+	if campaignID <= 0 {
+		return nil, ParamError{Param: "campaignID"}
+	}
+
+	var resp struct {
+		Campaign Campaign `json:"campaign"`
+	}
+	if err := c.requestJSON(ctx, "GET", formatPath("/v1/campaigns/%d", campaignID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Campaign, nil
+}
+
+// ListCampaignActions returns a page of a campaign's actions.
+// See https://docs.customer.io/api/app/#operation/listCampaignActions
+func (c *APIClient) ListCampaignActions(ctx context.Context, campaignID int, page PageOptions) (*ActionList, error) {
+	if campaignID <= 0 {
+		return nil, ParamError{Param: "campaignID"}
+	}
+
+	var list ActionList
+	if err := c.requestJSON(ctx, "GET", withQuery(formatPath("/v1/campaigns/%d/actions", campaignID), page.values()), nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetCampaignMetrics returns a campaign's message metrics over the queried time range.
+// See https://docs.customer.io/api/app/#operation/campaignMetrics
+func (c *APIClient) GetCampaignMetrics(ctx context.Context, campaignID int, query MetricsQuery) (*Metrics, error) {
+	if campaignID <= 0 {
+		return nil, ParamError{Param: "campaignID"}
+	}
+
+	var resp metricsResponse
+	if err := c.requestJSON(ctx, "GET", withQuery(formatPath("/v1/campaigns/%d/metrics", campaignID), query.values()), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Metric.Series, nil
+}
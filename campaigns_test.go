@@ -0,0 +1,101 @@
+package customerio_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestListCampaigns(t *testing.T) {
+	api := appServer(t, "GET", "/v1/campaigns?limit=1", `{"campaigns":[{"id":5,"name":"Onboarding","type":"segment","state":"running","active":true,"tags":["a"],"actions":[{"id":9}],"created":1500000000,"updated":1500000100}],"next":"abc"}`)
+
+	list, err := api.ListCampaigns(context.Background(), customerio.PageOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &customerio.CampaignList{
+		Campaigns: []customerio.Campaign{{
+			ID:        5,
+			Name:      "Onboarding",
+			Type:      "segment",
+			State:     "running",
+			Active:    true,
+			Tags:      []string{"a"},
+			Actions:   []int{9},
+			CreatedAt: time.Unix(1500000000, 0),
+			UpdatedAt: time.Unix(1500000100, 0),
+		}},
+		Next: "abc",
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("want %#v got %#v", want, list)
+	}
+}
+
+func TestGetCampaign(t *testing.T) {
+	api := appServer(t, "GET", "/v1/campaigns/5", `{"campaign":{"id":5,"name":"Onboarding"}}`)
+
+	campaign, err := api.GetCampaign(context.Background(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if campaign.ID != 5 || campaign.Name != "Onboarding" {
+		t.Errorf("unexpected campaign %#v", campaign)
+	}
+
+	_, err = api.GetCampaign(context.Background(), 0)
+	checkParamError(t, err, "campaignID")
+}
+
+func TestListCampaignActions(t *testing.T) {
+	api := appServer(t, "GET", "/v1/campaigns/5/actions?start=abc", `{"actions":[{"id":9,"campaign_id":5,"type":"email","name":"Welcome","subject":"Hi","created":1500000000}]}`)
+
+	list, err := api.ListCampaignActions(context.Background(), 5, customerio.PageOptions{Start: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &customerio.ActionList{
+		Actions: []customerio.Action{{
+			ID:         9,
+			CampaignID: 5,
+			Type:       "email",
+			Name:       "Welcome",
+			Subject:    "Hi",
+			CreatedAt:  time.Unix(1500000000, 0),
+		}},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("want %#v got %#v", want, list)
+	}
+}
+
+func TestGetCampaignMetrics(t *testing.T) {
+	api := appServer(t, "GET", "/v1/campaigns/5/metrics?end=1500086400&res=days&start=1500000000&type=email", `{"metric":{"series":{"sent":[3,4],"delivered":[3,3],"opened":[1,2],"clicked":[0,1],"converted":[0,0],"bounced":[0,1]}}}`)
+
+	metrics, err := api.GetCampaignMetrics(context.Background(), 5, customerio.MetricsQuery{
+		Start:  time.Unix(1500000000, 0),
+		End:    time.Unix(1500086400, 0),
+		Period: customerio.MetricsPeriodDays,
+		Type:   "email",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &customerio.Metrics{
+		Sent:      []int{3, 4},
+		Delivered: []int{3, 3},
+		Opened:    []int{1, 2},
+		Clicked:   []int{0, 1},
+		Converted: []int{0, 0},
+		Bounced:   []int{0, 1},
+	}
+	if !reflect.DeepEqual(metrics, want) {
+		t.Errorf("want %#v got %#v", want, metrics)
+	}
+}
@@ -0,0 +1,82 @@
+package customerio
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxSegmentMembershipIDs is the largest number of ids Customer.io accepts
+	// in a single add_customers or remove_customers request. Larger inputs to
+	// AddPeopleToSegment and RemovePeopleFromSegment are split automatically.
+	MaxSegmentMembershipIDs = 1000
+
+	// MaxBroadcastRecipients is the largest number of direct recipients
+	// (Ids, Emails or PerUserData entries) Customer.io accepts in a single
+	// broadcast trigger. Larger inputs to TriggerBroadcast are split into
+	// multiple triggers automatically.
+	MaxBroadcastRecipients = 10000
+)
+
+// ChunkError describes one failed request within an operation that was split
+// into several requests.
+type ChunkError struct {
+	// Index is the zero-based position of the chunk within the operation.
+	Index int
+	// IDs lists the recipients that were part of the failed chunk.
+	IDs []string
+	// Err is the error returned for the chunk.
+	Err error
+}
+
+func (e ChunkError) Error() string {
+	return fmt.Sprintf("chunk %d (%d ids): %v", e.Index, len(e.IDs), e.Err)
+}
+
+func (e ChunkError) Unwrap() error { return e.Err }
+
+// PartialFailureError is returned when some, but not necessarily all, of the
+// chunks of a split operation fail. Chunks not listed in Failed succeeded.
+type PartialFailureError struct {
+	// Chunks is the total number of chunks the operation was split into.
+	Chunks int
+	// Failed lists every chunk that failed, in order.
+	Failed []ChunkError
+}
+
+func (e *PartialFailureError) Error() string {
+	msgs := make([]string, len(e.Failed))
+	for i, f := range e.Failed {
+		msgs[i] = f.Error()
+	}
+	return fmt.Sprintf("%d of %d chunks failed: %s", len(e.Failed), e.Chunks, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the error of every failed chunk so errors.Is and errors.As
+// can match them.
+func (e *PartialFailureError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f
+	}
+	return errs
+}
+
+// FailedIDs returns the recipients of every failed chunk.
+func (e *PartialFailureError) FailedIDs() []string {
+	var ids []string
+	for _, f := range e.Failed {
+		ids = append(ids, f.IDs...)
+	}
+	return ids
+}
+
+// chunk splits s into consecutive slices of at most size elements. The
+// returned slices share s's backing array.
+func chunk[T any](s []T, size int) [][]T {
+	chunks := make([][]T, 0, (len(s)+size-1)/size)
+	for size < len(s) {
+		s, chunks = s[size:], append(chunks, s[:size:size])
+	}
+	return append(chunks, s)
+}
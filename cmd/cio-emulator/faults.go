@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// faults configures error injection. It is set with flags at startup and can
+// be replaced at runtime through the /_emulator/faults endpoint.
+type faults struct {
+	// PathPrefix limits injection to requests whose path has this prefix.
+	PathPrefix string `json:"path_prefix,omitempty"`
+	// Status is returned instead of handling the request, with probability
+	// Rate (1 when Rate is zero).
+	Status int     `json:"status,omitempty"`
+	Rate   float64 `json:"rate,omitempty"`
+	// Latency is added before every matching request.
+	Latency duration `json:"latency,omitempty"`
+	// RateLimit is the number of matching requests allowed per second before
+	// responding 429 Too Many Requests.
+	RateLimit int `json:"rate_limit,omitempty"`
+}
+
+// duration is a time.Duration that encodes as a string such as "250ms".
+type duration time.Duration
+
+func (d duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	*d = duration(v)
+	return err
+}
+
+// injector applies the current faults to incoming requests.
+type injector struct {
+	mu          sync.Mutex
+	cfg         faults
+	window      time.Time
+	windowCount int
+}
+
+// inject applies latency and reports whether the request should fail, and
+// with which status.
+func (in *injector) inject(req *http.Request) (int, bool) {
+	in.mu.Lock()
+	cfg := in.cfg
+	if !strings.HasPrefix(req.URL.Path, cfg.PathPrefix) {
+		in.mu.Unlock()
+		return 0, false
+	}
+
+	limited := false
+	if cfg.RateLimit > 0 {
+		now := time.Now()
+		if now.Sub(in.window) >= time.Second {
+			in.window, in.windowCount = now, 0
+		}
+		in.windowCount++
+		limited = in.windowCount > cfg.RateLimit
+	}
+	in.mu.Unlock()
+
+	if cfg.Latency > 0 {
+		select {
+		case <-time.After(time.Duration(cfg.Latency)):
+		case <-req.Context().Done():
+		}
+	}
+	if limited {
+		return http.StatusTooManyRequests, true
+	}
+	if cfg.Status != 0 && (cfg.Rate == 0 || rand.Float64() < cfg.Rate) {
+		return cfg.Status, true
+	}
+	return 0, false
+}
+
+func (in *injector) get() faults {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.cfg
+}
+
+func (in *injector) set(cfg faults) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.cfg = cfg
+	in.window, in.windowCount = time.Time{}, 0
+}
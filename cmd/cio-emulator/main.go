@@ -0,0 +1,76 @@
+// Command cio-emulator runs a local stand-in for the Customer.io Track and
+// App APIs, for integration tests in any language.
+//
+//	cio-emulator -addr :8080 -site-id site -track-api-key key -app-api-key appkey
+//
+// It implements the Track v1 customer, event, device, merge and segment
+// endpoints, the Track v2 entity and batch endpoints, and the App API
+// transactional send, broadcast trigger and message lookup endpoints, keeping
+// all state in memory. Point clients at it with customerio.WithURL.
+//
+// Track requests must use Basic auth with the site ID and Track API key, and
+// App API requests Bearer auth with the App API key; leaving a credential
+// unset disables that check.
+//
+// Control endpoints:
+//
+//	GET    /_emulator/state   dump the in-memory state as JSON
+//	DELETE /_emulator/state   reset the state
+//	GET    /_emulator/faults  show the current error injection settings
+//	PUT    /_emulator/faults  replace them, e.g. {"status": 500, "rate": 0.1, "latency": "200ms", "rate_limit": 10, "path_prefix": "/v1/send"}
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+func main() {
+	var (
+		addr      = flag.String("addr", envOr("CIO_EMULATOR_ADDR", ":8080"), "listen address")
+		siteID    = flag.String("site-id", os.Getenv("CUSTOMERIO_SITE_ID"), "accepted Track API site ID")
+		trackKey  = flag.String("track-api-key", os.Getenv("CUSTOMERIO_TRACK_API_KEY"), "accepted Track API key")
+		appKey    = flag.String("app-api-key", os.Getenv("CUSTOMERIO_APP_API_KEY"), "accepted App API key")
+		status    = flag.Int("fail-status", 0, "status code to inject")
+		rate      = flag.Float64("fail-rate", 0, "probability of injecting -fail-status (0 means always)")
+		latency   = flag.Duration("latency", 0, "latency added to every request")
+		rateLimit = flag.Int("rate-limit", 0, "requests per second allowed before responding 429 (0 disables)")
+	)
+	flag.Parse()
+
+	srv := &http.Server{
+		Addr: *addr,
+		Handler: newServer(
+			credentials{SiteID: *siteID, TrackAPIKey: *trackKey, AppAPIKey: *appKey},
+			faults{Status: *status, Rate: *rate, Latency: duration(*latency), RateLimit: *rateLimit},
+		),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+
+	log.Printf("cio-emulator listening on %s", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,511 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+// credentials are the keys the emulator accepts. An empty value disables
+// checking for that scheme.
+type credentials struct {
+	SiteID      string
+	TrackAPIKey string
+	AppAPIKey   string
+}
+
+type server struct {
+	creds  credentials
+	faults *injector
+	mux    *http.ServeMux
+
+	mu    sync.Mutex
+	state *state
+}
+
+func newServer(creds credentials, f faults) *server {
+	s := &server{
+		creds:  creds,
+		faults: &injector{cfg: f},
+		mux:    http.NewServeMux(),
+		state:  newState(),
+	}
+
+	// Track API v1.
+	s.track("PUT /api/v1/customers/{id}", s.identify)
+	s.track("DELETE /api/v1/customers/{id}", s.deleteCustomer)
+	s.track("POST /api/v1/customers/{id}/events", s.trackEvent)
+	s.track("POST /api/v1/events", s.trackAnonymous)
+	s.track("PUT /api/v1/customers/{id}/devices", s.addDevice)
+	s.track("DELETE /api/v1/customers/{id}/devices/{device}", s.deleteDevice)
+	s.track("POST /api/v1/merge_customers", s.mergeCustomers)
+	s.track("POST /api/v1/segments/{segment}/add_customers", s.segmentMembership(true))
+	s.track("POST /api/v1/segments/{segment}/remove_customers", s.segmentMembership(false))
+	s.track("GET /api/v1/accounts/region", s.accountRegion)
+
+	// Track API v2.
+	s.track("POST /api/v2/entity", s.entity)
+	s.track("POST /api/v2/batch", s.batch)
+
+	// App API.
+	s.appLimit("POST /v1/send/{type}", maxSendBodySize, s.send)
+	s.app("POST /v1/campaigns/{broadcast}/triggers", s.triggerBroadcast)
+	s.app("GET /v1/campaigns/{broadcast}/triggers/{trigger}", s.getTrigger)
+	s.app("GET /v1/campaigns/{broadcast}/triggers/{trigger}/errors", s.getTriggerErrors)
+	s.app("GET /v1/messages/{delivery}", s.getMessage)
+
+	// Emulator control.
+	s.mux.HandleFunc("GET /_emulator/state", s.getState)
+	s.mux.HandleFunc("DELETE /_emulator/state", s.resetState)
+	s.mux.HandleFunc("GET /_emulator/faults", s.getFaults)
+	s.mux.HandleFunc("PUT /_emulator/faults", s.setFaults)
+
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mux.ServeHTTP(w, req)
+}
+
+// handlerFunc handles an authenticated request with its decoded JSON body.
+// It runs with s.mu held and returns the response status and body.
+type handlerFunc func(req *http.Request, body map[string]any) (int, any)
+
+const (
+	// maxBodySize bounds request bodies unless a route sets its own limit.
+	maxBodySize = 1 << 20
+	// maxSendBodySize fits a transactional email carrying the largest
+	// attachments the library accepts, base64 encoded, and the rest of the
+	// request.
+	maxSendBodySize = (customerio.MaxAttachmentsSize+2)/3*4 + maxBodySize
+)
+
+func (s *server) track(pattern string, h handlerFunc) {
+	s.mux.Handle(pattern, s.wrap(s.checkBasic, maxBodySize, h))
+}
+
+func (s *server) app(pattern string, h handlerFunc) {
+	s.appLimit(pattern, maxBodySize, h)
+}
+
+// appLimit registers an App API route accepting bodies of up to limit bytes.
+func (s *server) appLimit(pattern string, limit int64, h handlerFunc) {
+	s.mux.Handle(pattern, s.wrap(s.checkBearer, limit, h))
+}
+
+func (s *server) wrap(auth func(*http.Request) bool, limit int64, h handlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if status, fail := s.faults.inject(req); fail {
+			writeJSON(w, status, errorBody("injected fault"))
+			return
+		}
+		if !auth(req) {
+			writeJSON(w, http.StatusUnauthorized, errorBody("unauthorized"))
+			return
+		}
+
+		var body map[string]any
+		b, err := io.ReadAll(http.MaxBytesReader(w, req.Body, limit))
+		if err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			writeJSON(w, status, errorBody(err.Error()))
+			return
+		}
+		if len(b) > 0 {
+			if err := json.Unmarshal(b, &body); err != nil {
+				writeJSON(w, http.StatusBadRequest, errorBody("invalid JSON: "+err.Error()))
+				return
+			}
+		}
+
+		s.mu.Lock()
+		status, resp := h(req, body)
+		s.mu.Unlock()
+		writeJSON(w, status, resp)
+	})
+}
+
+func (s *server) checkBasic(req *http.Request) bool {
+	if s.creds.SiteID == "" && s.creds.TrackAPIKey == "" {
+		return true
+	}
+	scheme, encoded, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || scheme != "Basic" {
+		return false
+	}
+	// Accept both encodings; this library sends URL-safe base64.
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		decoded, err = base64.URLEncoding.DecodeString(encoded)
+	}
+	if err != nil {
+		return false
+	}
+	return string(decoded) == s.creds.SiteID+":"+s.creds.TrackAPIKey
+}
+
+func (s *server) checkBearer(req *http.Request) bool {
+	if s.creds.AppAPIKey == "" {
+		return true
+	}
+	return req.Header.Get("Authorization") == "Bearer "+s.creds.AppAPIKey
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v != nil {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+}
+
+func errorBody(msg string) map[string]any {
+	return map[string]any{"meta": map[string]any{"error": msg}}
+}
+
+func badRequest(format string, args ...any) (int, any) {
+	return http.StatusBadRequest, errorBody(fmt.Sprintf(format, args...))
+}
+
+func (s *server) identify(req *http.Request, body map[string]any) (int, any) {
+	c := s.state.customer(req.PathValue("id"))
+	for k, v := range body {
+		c.Attributes[k] = v
+	}
+	return http.StatusOK, nil
+}
+
+// accountRegion reports the emulator itself as a US workspace.
+func (s *server) accountRegion(req *http.Request, _ map[string]any) (int, any) {
+	return http.StatusOK, map[string]any{
+		"url":            "http://" + req.Host,
+		"data_center":    "us",
+		"environment_id": 1,
+	}
+}
+
+func (s *server) deleteCustomer(req *http.Request, _ map[string]any) (int, any) {
+	delete(s.state.Customers, req.PathValue("id"))
+	return http.StatusOK, nil
+}
+
+func newEvent(body map[string]any) (event, error) {
+	e := event{ReceivedAt: time.Now().UTC()}
+	e.Name, _ = body["name"].(string)
+	if e.Name == "" {
+		return e, fmt.Errorf("name is required")
+	}
+	e.ID, _ = body["id"].(string)
+	e.Type, _ = body["type"].(string)
+	e.AnonymousID, _ = body["anonymous_id"].(string)
+	e.Data, _ = body["data"].(map[string]any)
+	if ts, ok := body["timestamp"].(float64); ok {
+		e.Timestamp = int64(ts)
+	}
+	return e, nil
+}
+
+func (s *server) trackEvent(req *http.Request, body map[string]any) (int, any) {
+	e, err := newEvent(body)
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	c := s.state.customer(req.PathValue("id"))
+	c.Events = append(c.Events, e)
+	return http.StatusOK, nil
+}
+
+func (s *server) trackAnonymous(_ *http.Request, body map[string]any) (int, any) {
+	e, err := newEvent(body)
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	s.state.AnonymousEvents = append(s.state.AnonymousEvents, e)
+	return http.StatusOK, nil
+}
+
+func (s *server) addDevice(req *http.Request, body map[string]any) (int, any) {
+	d, _ := body["device"].(map[string]any)
+	id, _ := d["id"].(string)
+	platform, _ := d["platform"].(string)
+	if id == "" || platform == "" {
+		return badRequest("device id and platform are required")
+	}
+	attrs, _ := d["attributes"].(map[string]any)
+
+	c := s.state.customer(req.PathValue("id"))
+	c.Devices[id] = &device{ID: id, Platform: platform, LastUsed: d["last_used"], Attributes: attrs}
+	return http.StatusOK, nil
+}
+
+func (s *server) deleteDevice(req *http.Request, _ map[string]any) (int, any) {
+	if c, ok := s.state.Customers[req.PathValue("id")]; ok {
+		delete(c.Devices, req.PathValue("device"))
+	}
+	return http.StatusOK, nil
+}
+
+func (s *server) mergeCustomers(_ *http.Request, body map[string]any) (int, any) {
+	primary, _ := body["primary"].(map[string]any)
+	secondary, _ := body["secondary"].(map[string]any)
+	if len(primary) != 1 || len(secondary) != 1 {
+		return badRequest("primary and secondary must each have exactly one identifier")
+	}
+	s.state.Merges = append(s.state.Merges, body)
+
+	// Only id identifiers can be resolved to stored customers.
+	pid, _ := primary["id"].(string)
+	sid, _ := secondary["id"].(string)
+	if src, ok := s.state.Customers[sid]; ok && pid != "" {
+		dst := s.state.customer(pid)
+		for k, v := range src.Attributes {
+			if _, exists := dst.Attributes[k]; !exists {
+				dst.Attributes[k] = v
+			}
+		}
+		for k, d := range src.Devices {
+			dst.Devices[k] = d
+		}
+		dst.Events = append(dst.Events, src.Events...)
+		delete(s.state.Customers, sid)
+	}
+	return http.StatusOK, nil
+}
+
+func (s *server) segmentMembership(add bool) handlerFunc {
+	return func(req *http.Request, body map[string]any) (int, any) {
+		segmentID, err := strconv.Atoi(req.PathValue("segment"))
+		if err != nil || segmentID <= 0 {
+			return badRequest("invalid segment id")
+		}
+		raw, _ := body["ids"].([]any)
+		if len(raw) == 0 {
+			return badRequest("ids are required")
+		}
+		if len(raw) > 1000 {
+			return badRequest("at most 1000 ids are allowed per request")
+		}
+		ids := make([]string, 0, len(raw))
+		for _, v := range raw {
+			ids = append(ids, fmt.Sprint(v))
+		}
+		s.state.updateSegment(segmentID, ids, add)
+		return http.StatusOK, nil
+	}
+}
+
+func (s *server) entity(req *http.Request, body map[string]any) (int, any) {
+	if err := s.applyEntity(body); err != nil {
+		return badRequest("%v", err)
+	}
+	return http.StatusOK, nil
+}
+
+func (s *server) batch(req *http.Request, body map[string]any) (int, any) {
+	items, _ := body["batch"].([]any)
+	if len(items) == 0 {
+		return badRequest("batch is required")
+	}
+	var errs []map[string]any
+	for i, item := range items {
+		m, _ := item.(map[string]any)
+		if err := s.applyEntity(m); err != nil {
+			errs = append(errs, map[string]any{"batch_index": i, "reason": err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return http.StatusMultiStatus, map[string]any{"errors": errs}
+	}
+	return http.StatusOK, nil
+}
+
+// applyEntity applies a single Track v2 entity operation for a person.
+func (s *server) applyEntity(body map[string]any) error {
+	if typ, _ := body["type"].(string); typ != "person" {
+		return fmt.Errorf("unsupported entity type %q", typ)
+	}
+	identifiers, _ := body["identifiers"].(map[string]any)
+	id := fmt.Sprint(identifiers["id"])
+	if identifiers["id"] == nil {
+		email, _ := identifiers["email"].(string)
+		if email == "" {
+			return fmt.Errorf("identifiers must include id or email")
+		}
+		id = email
+	}
+
+	switch action, _ := body["action"].(string); action {
+	case "identify":
+		c := s.state.customer(id)
+		attrs, _ := body["attributes"].(map[string]any)
+		for k, v := range attrs {
+			c.Attributes[k] = v
+		}
+	case "delete":
+		delete(s.state.Customers, id)
+	case "event":
+		e, err := newEvent(body)
+		if err != nil {
+			return err
+		}
+		e.Data, _ = body["attributes"].(map[string]any)
+		c := s.state.customer(id)
+		c.Events = append(c.Events, e)
+	case "add_device":
+		d, _ := body["device"].(map[string]any)
+		token, _ := d["token"].(string)
+		platform, _ := d["platform"].(string)
+		if token == "" || platform == "" {
+			return fmt.Errorf("device token and platform are required")
+		}
+		attrs, _ := d["attributes"].(map[string]any)
+		s.state.customer(id).Devices[token] = &device{ID: token, Platform: platform, LastUsed: d["last_used"], Attributes: attrs}
+	case "delete_device":
+		d, _ := body["device"].(map[string]any)
+		token, _ := d["token"].(string)
+		if c, ok := s.state.Customers[id]; ok {
+			delete(c.Devices, token)
+		}
+	default:
+		return fmt.Errorf("unsupported action %q", action)
+	}
+	return nil
+}
+
+var sendTypes = map[string]bool{"email": true, "push": true, "sms": true, "inbox_message": true, "in_app": true}
+
+func (s *server) send(req *http.Request, body map[string]any) (int, any) {
+	typ := req.PathValue("type")
+	if !sendTypes[typ] {
+		return http.StatusNotFound, errorBody("unknown message type")
+	}
+	identifiers, _ := body["identifiers"].(map[string]any)
+	if len(identifiers) != 1 {
+		return badRequest("identifiers must contain exactly one of id, email or cio_id")
+	}
+	if body["transactional_message_id"] == nil && typ == "email" && (body["body"] == nil || body["subject"] == nil || body["from"] == nil) {
+		return badRequest("transactional_message_id or body, subject and from are required")
+	}
+
+	d := &delivery{ID: newDeliveryID(), Type: typ, QueuedAt: time.Now().Unix(), Request: body}
+	s.state.Deliveries = append(s.state.Deliveries, d)
+	return http.StatusOK, map[string]any{"delivery_id": d.ID, "queued_at": d.QueuedAt}
+}
+
+func (s *server) triggerBroadcast(req *http.Request, body map[string]any) (int, any) {
+	broadcastID, err := strconv.Atoi(req.PathValue("broadcast"))
+	if err != nil || broadcastID <= 0 {
+		return badRequest("invalid broadcast id")
+	}
+	for _, key := range []string{"ids", "emails", "per_user_data"} {
+		if list, _ := body[key].([]any); len(list) > 10000 {
+			return badRequest("at most 10000 %s are allowed per trigger", key)
+		}
+	}
+
+	s.state.nextTriggerID++
+	t := &trigger{
+		ID:          s.state.nextTriggerID,
+		BroadcastID: broadcastID,
+		CreatedAt:   time.Now().Unix(),
+		// Triggers complete immediately; there is no campaign to run.
+		Processed: true,
+		Request:   body,
+	}
+	s.state.Triggers = append(s.state.Triggers, t)
+	return http.StatusOK, map[string]any{"id": t.ID}
+}
+
+func (s *server) findTrigger(req *http.Request) *trigger {
+	broadcastID, _ := strconv.Atoi(req.PathValue("broadcast"))
+	triggerID, _ := strconv.Atoi(req.PathValue("trigger"))
+	for _, t := range s.state.Triggers {
+		if t.ID == triggerID && t.BroadcastID == broadcastID {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *server) getTrigger(req *http.Request, _ map[string]any) (int, any) {
+	t := s.findTrigger(req)
+	if t == nil {
+		return http.StatusNotFound, errorBody("trigger not found")
+	}
+	return http.StatusOK, map[string]any{
+		"id":           t.ID,
+		"campaign_id":  t.BroadcastID,
+		"created_at":   t.CreatedAt,
+		"processed":    t.Processed,
+		"processed_at": t.CreatedAt,
+		"status":       "complete",
+	}
+}
+
+func (s *server) getTriggerErrors(req *http.Request, _ map[string]any) (int, any) {
+	if s.findTrigger(req) == nil {
+		return http.StatusNotFound, errorBody("trigger not found")
+	}
+	return http.StatusOK, map[string]any{"errors": []any{}}
+}
+
+func (s *server) getMessage(req *http.Request, _ map[string]any) (int, any) {
+	id := req.PathValue("delivery")
+	for _, d := range s.state.Deliveries {
+		if d.ID != id {
+			continue
+		}
+		msg := map[string]any{
+			"id":                   d.ID,
+			"type":                 d.Type,
+			"created":              d.QueuedAt,
+			"customer_identifiers": d.Request["identifiers"],
+			"metrics":              map[string]any{"sent": d.QueuedAt},
+		}
+		if to, ok := d.Request["to"]; ok {
+			msg["recipient"] = to
+		}
+		return http.StatusOK, map[string]any{"message": msg}
+	}
+	return http.StatusNotFound, errorBody("message not found")
+}
+
+func (s *server) getState(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.state)
+}
+
+func (s *server) resetState(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	s.state = newState()
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) getFaults(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.faults.get())
+}
+
+func (s *server) setFaults(w http.ResponseWriter, req *http.Request) {
+	var f faults
+	if err := json.NewDecoder(req.Body).Decode(&f); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorBody(err.Error()))
+		return
+	}
+	s.faults.set(f)
+	writeJSON(w, http.StatusOK, f)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+var testCreds = credentials{SiteID: "site", TrackAPIKey: "trackkey", AppAPIKey: "appkey"}
+
+func newTestServer(t *testing.T) (*server, string) {
+	t.Helper()
+	s := newServer(testCreds, faults{})
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, srv.URL
+}
+
+func TestTrackOperations(t *testing.T) {
+	s, url := newTestServer(t)
+	ctx := context.Background()
+	track := customerio.NewTrackClient("site", "trackkey", customerio.WithURL(url))
+
+	if err := track.IdentifyCtx(ctx, "1/a", map[string]any{"plan": "pro"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.TrackCtx(ctx, "1/a", "purchase", map[string]any{"total": 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.AddDeviceCtx(ctx, "1/a", "tok", "ios", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.AddPeopleToSegment(ctx, 4, []string{"1/a", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.RemovePeopleFromSegment(ctx, 4, []string{"2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.TrackAnonymousCtx(ctx, "anon", "visit", nil); err != nil {
+		t.Fatal(err)
+	}
+	if region, err := track.AccountRegion(ctx); err != nil || region.DataCenter != "us" {
+		t.Fatalf("unexpected region %+v, %v", region, err)
+	}
+
+	c := s.state.Customers["1/a"]
+	if c == nil || c.Attributes["plan"] != "pro" || len(c.Events) != 1 || c.Devices["tok"] == nil {
+		t.Fatalf("unexpected customer %#v", c)
+	}
+	if got := s.state.Segments[4]; len(got) != 1 || got[0] != "1/a" {
+		t.Errorf("unexpected segment members %v", got)
+	}
+	if len(s.state.AnonymousEvents) != 1 {
+		t.Errorf("expected anonymous event")
+	}
+
+	if err := track.DeleteCtx(ctx, "1/a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.state.Customers["1/a"]; ok {
+		t.Error("expected customer to be deleted")
+	}
+
+	bad := customerio.NewTrackClient("site", "wrong", customerio.WithURL(url))
+	var cerr *customerio.CustomerIOError
+	if err := bad.IdentifyCtx(ctx, "1", nil); !errors.As(err, &cerr) || cerr.StatusCode() != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %v", err)
+	}
+}
+
+func TestAppOperations(t *testing.T) {
+	s, url := newTestServer(t)
+	ctx := context.Background()
+	api := customerio.NewAPIClient("appkey", customerio.WithURL(url))
+
+	resp, err := api.SendEmail(ctx, &customerio.SendEmailRequest{
+		TransactionalMessageID: "3",
+		Identifiers:            map[string]string{"id": "1"},
+		To:                     "a@example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.DeliveryID == "" || len(s.state.Deliveries) != 1 {
+		t.Fatalf("unexpected response %#v", resp)
+	}
+
+	msg, err := api.GetMessage(ctx, resp.DeliveryID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Recipient != "a@example.com" || msg.Metrics.Sent.IsZero() {
+		t.Errorf("unexpected message %#v", msg)
+	}
+
+	br, err := api.TriggerBroadcast(ctx, 7, nil, customerio.BroadcastRecipients{Ids: []string{"1"}}, customerio.BroadcastOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trigger, err := api.WaitForBroadcastTrigger(ctx, 7, br.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trigger.Processed {
+		t.Errorf("unexpected trigger %#v", trigger)
+	}
+
+	bad := customerio.NewAPIClient("wrong", customerio.WithURL(url))
+	var terr *customerio.TransactionalError
+	if _, err := bad.SendSMS(ctx, &customerio.SendSMSRequest{TransactionalMessageID: "3", Identifiers: map[string]string{"id": "1"}}); !errors.As(err, &terr) || terr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %v", err)
+	}
+}
+
+func TestSendLargeAttachments(t *testing.T) {
+	s, url := newTestServer(t)
+	api := customerio.NewAPIClient("appkey", customerio.WithURL(url))
+
+	req := &customerio.SendEmailRequest{
+		TransactionalMessageID: "3",
+		Identifiers:            map[string]string{"id": "1"},
+		Body:                   strings.Repeat("<p>receipt</p>", 10000),
+	}
+	if err := req.Attach("receipt.pdf", bytes.NewReader(make([]byte, customerio.MaxAttachmentsSize-1))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.SendEmail(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.state.Deliveries) != 1 {
+		t.Errorf("expected one delivery, got %d", len(s.state.Deliveries))
+	}
+}
+
+func TestFaultInjection(t *testing.T) {
+	_, url := newTestServer(t)
+	ctx := context.Background()
+	track := customerio.NewTrackClient("site", "trackkey", customerio.WithURL(url))
+
+	req, _ := http.NewRequest("PUT", url+"/_emulator/faults", strings.NewReader(`{"status":503,"path_prefix":"/api/v1/customers"}`))
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	var cerr *customerio.CustomerIOError
+	if err := track.IdentifyCtx(ctx, "1", nil); !errors.As(err, &cerr) || cerr.StatusCode() != http.StatusServiceUnavailable {
+		t.Errorf("expected injected 503, got %v", err)
+	}
+	if err := track.TrackAnonymousCtx(ctx, "a", "visit", nil); err != nil {
+		t.Errorf("expected requests outside path_prefix to succeed, got %v", err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	s := newServer(credentials{}, faults{RateLimit: 2})
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	track := customerio.NewTrackClient("any", "any", customerio.WithURL(srv.URL))
+	var limited int
+	for i := 0; i < 4; i++ {
+		var cerr *customerio.CustomerIOError
+		if err := track.IdentifyCtx(context.Background(), "1", nil); errors.As(err, &cerr) && cerr.StatusCode() == http.StatusTooManyRequests {
+			limited++
+		}
+	}
+	if limited != 2 {
+		t.Errorf("expected 2 rate limited requests, got %d", limited)
+	}
+}
+
+func TestStateEndpoint(t *testing.T) {
+	_, url := newTestServer(t)
+	track := customerio.NewTrackClient("site", "trackkey", customerio.WithURL(url))
+	if err := track.Identify("1", map[string]any{"a": "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := http.Get(url + "/_emulator/state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	var got struct {
+		Customers map[string]struct {
+			Attributes map[string]any `json:"attributes"`
+		} `json:"customers"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Customers["1"].Attributes["a"] != "b" {
+		t.Errorf("unexpected state %#v", got)
+	}
+}
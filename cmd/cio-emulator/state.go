@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// state is the emulator's in-memory workspace. Its JSON form is served by the
+// inspection endpoint. All access is guarded by server.mu.
+type state struct {
+	Customers       map[string]*customer `json:"customers"`
+	AnonymousEvents []event              `json:"anonymous_events"`
+	Segments        map[int][]string     `json:"segments"`
+	Merges          []map[string]any     `json:"merges"`
+	Deliveries      []*delivery          `json:"deliveries"`
+	Triggers        []*trigger           `json:"broadcast_triggers"`
+
+	nextTriggerID int
+}
+
+type customer struct {
+	ID         string             `json:"id"`
+	Attributes map[string]any     `json:"attributes"`
+	Devices    map[string]*device `json:"devices"`
+	Events     []event            `json:"events"`
+}
+
+type device struct {
+	ID         string         `json:"id"`
+	Platform   string         `json:"platform"`
+	LastUsed   any            `json:"last_used,omitempty"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+type event struct {
+	Name        string         `json:"name"`
+	ID          string         `json:"id,omitempty"`
+	Type        string         `json:"type,omitempty"`
+	Timestamp   int64          `json:"timestamp,omitempty"`
+	AnonymousID string         `json:"anonymous_id,omitempty"`
+	Data        map[string]any `json:"data,omitempty"`
+	ReceivedAt  time.Time      `json:"received_at"`
+}
+
+type delivery struct {
+	ID       string         `json:"delivery_id"`
+	Type     string         `json:"type"`
+	QueuedAt int64          `json:"queued_at"`
+	Request  map[string]any `json:"request"`
+}
+
+type trigger struct {
+	ID          int            `json:"id"`
+	BroadcastID int            `json:"campaign_id"`
+	CreatedAt   int64          `json:"created_at"`
+	Processed   bool           `json:"processed"`
+	Request     map[string]any `json:"request"`
+}
+
+func newState() *state {
+	return &state{
+		Customers: map[string]*customer{},
+		Segments:  map[int][]string{},
+	}
+}
+
+func (s *state) customer(id string) *customer {
+	c, ok := s.Customers[id]
+	if !ok {
+		c = &customer{ID: id, Attributes: map[string]any{}, Devices: map[string]*device{}}
+		s.Customers[id] = c
+	}
+	return c
+}
+
+func (s *state) updateSegment(segmentID int, ids []string, add bool) {
+	members := map[string]bool{}
+	for _, id := range s.Segments[segmentID] {
+		members[id] = true
+	}
+	for _, id := range ids {
+		if add {
+			members[id] = true
+		} else {
+			delete(members, id)
+		}
+	}
+
+	list := make([]string, 0, len(members))
+	for id := range members {
+		list = append(list, id)
+	}
+	sort.Strings(list)
+	s.Segments[segmentID] = list
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,111 @@
+// Command cio-import bulk imports customers or events into Customer.io from a
+// CSV or JSONL file.
+//
+//	CUSTOMERIO_SITE_ID=... CUSTOMERIO_TRACK_API_KEY=... \
+//	    cio-import -mapping events.json -format csv -checkpoint events.ckpt events.csv
+//
+// The mapping file is a JSON importer.Mapping, e.g.
+//
+//	{"type": "event", "customer_id": "user_id", "event_name": "event", "timestamp": "ts"}
+//
+// Input is read from stdin when no file is given. Rerunning with the same
+// checkpoint file resumes after the last fully imported row, and fails if the
+// input is a different file.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/customerio/go-customerio/v3"
+	"github.com/customerio/go-customerio/v3/importer"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "cio-import:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		format      = flag.String("format", "", "input format, csv or jsonl (default from file extension)")
+		mappingPath = flag.String("mapping", "", "path to the JSON mapping spec (required)")
+		concurrency = flag.Int("concurrency", importer.DefaultConcurrency, "maximum concurrent requests")
+		checkpoint  = flag.String("checkpoint", "", "path to the checkpoint file used to resume imports")
+		region      = flag.String("region", "", "workspace region, us or eu")
+		baseURL     = flag.String("url", "", "override the Track API base URL")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: cio-import -mapping spec.json [flags] [file]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *mappingPath == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	mapping, err := importer.LoadMapping(*mappingPath)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := customerio.ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	if *region != "" {
+		cfg.Region = customerio.Region(*region)
+	}
+	if *baseURL != "" {
+		cfg.URL = *baseURL
+	}
+	client, err := cfg.TrackClient()
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() == 1 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		in = f
+		if *format == "" {
+			*format = strings.TrimPrefix(filepath.Ext(f.Name()), ".")
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	res, err := importer.Import(ctx, client, in, importer.Options{
+		Format:         importer.Format(*format),
+		Mapping:        mapping,
+		Concurrency:    *concurrency,
+		CheckpointPath: *checkpoint,
+	})
+	if res != nil {
+		for _, f := range res.Failed {
+			fmt.Fprintln(os.Stderr, f)
+		}
+		fmt.Fprintf(os.Stderr, "imported %d, skipped %d, failed %d\n", res.Imported, res.Skipped, len(res.Failed))
+		if err == nil && len(res.Failed) > 0 {
+			err = fmt.Errorf("%d rows failed", len(res.Failed))
+		}
+	}
+	return err
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func identify(e *env, args []string) error {
+	id := e.flags.String("id", "", "customer ID")
+	data := e.flags.String("data", "", "JSON file of attributes, or - for stdin")
+	if err := e.flags.Parse(args); err != nil {
+		return err
+	}
+
+	var attributes map[string]any
+	if err := e.readJSON(*data, &attributes); err != nil {
+		return err
+	}
+	client, err := e.track()
+	if err != nil {
+		return err
+	}
+	if err := client.IdentifyCtx(e.ctx, *id, attributes); err != nil {
+		return err
+	}
+	return e.ok()
+}
+
+func track(e *env, args []string) error {
+	id := e.flags.String("id", "", "customer ID")
+	name := e.flags.String("name", "", "event name")
+	data := e.flags.String("data", "", "JSON file of event data, or - for stdin")
+	eventID := e.flags.String("event-id", "", "event ID (a ULID) for deduplication")
+	timestamp := e.flags.Int64("timestamp", 0, "event time as a Unix timestamp")
+	if err := e.flags.Parse(args); err != nil {
+		return err
+	}
+
+	var eventData map[string]any
+	if err := e.readJSON(*data, &eventData); err != nil {
+		return err
+	}
+	var opts []customerio.TrackOption
+	if *eventID != "" {
+		opts = append(opts, customerio.WithEventID(*eventID))
+	}
+	if *timestamp != 0 {
+		opts = append(opts, customerio.WithEventTimestamp(time.Unix(*timestamp, 0)))
+	}
+
+	client, err := e.track()
+	if err != nil {
+		return err
+	}
+	if err := client.TrackCtx(e.ctx, *id, *name, eventData, opts...); err != nil {
+		return err
+	}
+	return e.ok()
+}
+
+func deleteCustomer(e *env, args []string) error {
+	id := e.flags.String("id", "", "customer ID")
+	if err := e.flags.Parse(args); err != nil {
+		return err
+	}
+
+	client, err := e.track()
+	if err != nil {
+		return err
+	}
+	if err := client.DeleteCtx(e.ctx, *id); err != nil {
+		return err
+	}
+	return e.ok()
+}
+
+func merge(e *env, args []string) error {
+	primary := e.flags.String("primary", "", "profile to keep, as TYPE:VALUE (id, email or cio_id)")
+	secondary := e.flags.String("secondary", "", "profile to merge and delete, as TYPE:VALUE")
+	if err := e.flags.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := parseIdentifier(*primary)
+	if err != nil {
+		return err
+	}
+	s, err := parseIdentifier(*secondary)
+	if err != nil {
+		return err
+	}
+	client, err := e.track()
+	if err != nil {
+		return err
+	}
+	if err := client.MergeCustomersCtx(e.ctx, p, s); err != nil {
+		return err
+	}
+	return e.ok()
+}
+
+func addDevice(e *env, args []string) error {
+	id := e.flags.String("id", "", "customer ID")
+	device := e.flags.String("device", "", "device token")
+	platform := e.flags.String("platform", "", "device platform, ios or android")
+	data := e.flags.String("data", "", "JSON file of device attributes, or - for stdin")
+	if err := e.flags.Parse(args); err != nil {
+		return err
+	}
+
+	var attributes map[string]any
+	if err := e.readJSON(*data, &attributes); err != nil {
+		return err
+	}
+	client, err := e.track()
+	if err != nil {
+		return err
+	}
+	if err := client.AddDeviceCtx(e.ctx, *id, *device, *platform, attributes); err != nil {
+		return err
+	}
+	return e.ok()
+}
+
+func deleteDevice(e *env, args []string) error {
+	id := e.flags.String("id", "", "customer ID")
+	device := e.flags.String("device", "", "device token")
+	if err := e.flags.Parse(args); err != nil {
+		return err
+	}
+
+	client, err := e.track()
+	if err != nil {
+		return err
+	}
+	if err := client.DeleteDeviceCtx(e.ctx, *id, *device); err != nil {
+		return err
+	}
+	return e.ok()
+}
+
+// broadcastInput is the -data document of trigger-broadcast.
+type broadcastInput struct {
+	Data       map[string]any                 `json:"data"`
+	Recipients customerio.BroadcastRecipients `json:"recipients"`
+	Options    struct {
+		IDIgnoreMissing    *bool `json:"id_ignore_missing"`
+		EmailIgnoreMissing *bool `json:"email_ignore_missing"`
+		EmailAddDuplicates *bool `json:"email_add_duplicates"`
+	} `json:"options"`
+}
+
+func triggerBroadcast(e *env, args []string) error {
+	broadcastID := e.flags.Int("broadcast", 0, "broadcast ID")
+	data := e.flags.String("data", "", "JSON file of data, recipients and options, or - for stdin")
+	if err := e.flags.Parse(args); err != nil {
+		return err
+	}
+
+	var in broadcastInput
+	if err := e.readJSON(*data, &in); err != nil {
+		return err
+	}
+	client, err := e.api()
+	if err != nil {
+		return err
+	}
+	resp, err := client.TriggerBroadcast(e.ctx, *broadcastID, in.Data, in.Recipients, customerio.BroadcastOptions{
+		IDIgnoreMissing:    in.Options.IDIgnoreMissing,
+		EmailIgnoreMissing: in.Options.EmailIgnoreMissing,
+		EmailAddDuplicates: in.Options.EmailAddDuplicates,
+	})
+	if resp != nil {
+		if werr := e.writeJSON(map[string]any{"id": resp.ID, "trigger_ids": resp.TriggerIDs}); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// send reads a transactional request from -data, or stdin, into req and
+// writes the response of do as JSON.
+func send[R any](e *env, args []string, req *R, do func(context.Context, *customerio.APIClient, *R) (any, error)) error {
+	data := e.flags.String("data", "-", "JSON file of the send request, or - for stdin")
+	if err := e.flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := e.readJSON(*data, req); err != nil {
+		return err
+	}
+	client, err := e.api()
+	if err != nil {
+		return err
+	}
+	resp, err := do(e.ctx, client, req)
+	if err != nil {
+		return err
+	}
+	return e.writeJSON(resp)
+}
+
+// sendResult is the JSON output of the send-* commands.
+type sendResult struct {
+	DeliveryID string    `json:"delivery_id"`
+	QueuedAt   time.Time `json:"queued_at"`
+}
+
+func newSendResult(r customerio.TransactionalResponse) sendResult {
+	return sendResult{DeliveryID: r.DeliveryID, QueuedAt: r.QueuedAt}
+}
+
+func sendEmail(e *env, args []string) error {
+	return send(e, args, &customerio.SendEmailRequest{}, func(ctx context.Context, c *customerio.APIClient, req *customerio.SendEmailRequest) (any, error) {
+		resp, err := c.SendEmail(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return newSendResult(resp.TransactionalResponse), nil
+	})
+}
+
+func sendPush(e *env, args []string) error {
+	return send(e, args, &customerio.SendPushRequest{}, func(ctx context.Context, c *customerio.APIClient, req *customerio.SendPushRequest) (any, error) {
+		resp, err := c.SendPush(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return newSendResult(resp.TransactionalResponse), nil
+	})
+}
+
+func sendSMS(e *env, args []string) error {
+	return send(e, args, &customerio.SendSMSRequest{}, func(ctx context.Context, c *customerio.APIClient, req *customerio.SendSMSRequest) (any, error) {
+		resp, err := c.SendSMS(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return newSendResult(resp.TransactionalResponse), nil
+	})
+}
+
+func sendInApp(e *env, args []string) error {
+	return send(e, args, &customerio.SendInAppRequest{}, func(ctx context.Context, c *customerio.APIClient, req *customerio.SendInAppRequest) (any, error) {
+		resp, err := c.SendInApp(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return newSendResult(resp.TransactionalResponse), nil
+	})
+}
+
+func sendInbox(e *env, args []string) error {
+	return send(e, args, &customerio.SendInboxMessageRequest{}, func(ctx context.Context, c *customerio.APIClient, req *customerio.SendInboxMessageRequest) (any, error) {
+		resp, err := c.SendInboxMessage(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return newSendResult(resp.TransactionalResponse), nil
+	})
+}
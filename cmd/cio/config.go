@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+// defaultConfigPath returns the config file used when -config is not given.
+func defaultConfigPath() string {
+	if p := os.Getenv("CIO_CONFIG"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "cio", "config.json")
+}
+
+// loadConfig reads the config file at path, which may be missing unless
+// required, with CUSTOMERIO_* environment variables taking precedence.
+func loadConfig(path string, required bool) (customerio.Config, error) {
+	if path != "" {
+		c, err := customerio.LoadConfig(path)
+		if err == nil || required || !errors.Is(err, os.ErrNotExist) {
+			return c, err
+		}
+	}
+	return customerio.ConfigFromEnv()
+}
@@ -0,0 +1,227 @@
+// Command cio calls the Customer.io Track and App APIs from the command line.
+//
+//	cio [global flags] <command> [flags]
+//
+// Track API commands (need a site ID and Track API key):
+//
+//	identify          -id ID [-data FILE]
+//	track             -id ID -name NAME [-data FILE] [-event-id ID] [-timestamp UNIX]
+//	delete            -id ID
+//	merge             -primary TYPE:VALUE -secondary TYPE:VALUE
+//	add-device        -id ID -device TOKEN -platform ios|android [-data FILE]
+//	delete-device     -id ID -device TOKEN
+//
+// App API commands (need an App API key):
+//
+//	trigger-broadcast -broadcast ID [-data FILE]
+//	send-email        [-data FILE]
+//	send-push         [-data FILE]
+//	send-sms          [-data FILE]
+//	send-in-app       [-data FILE]
+//	send-inbox        [-data FILE]
+//
+// -data names a JSON file, or "-" for stdin: attributes for identify and
+// add-device, event data for track, the send request for send-*, and
+// {"data": ..., "recipients": ..., "options": ...} for trigger-broadcast.
+// Send commands read stdin when -data is omitted.
+//
+// Credentials are read from the CUSTOMERIO_* environment variables or a JSON
+// config file, as described by customerio.LoadConfig. The file defaults to
+// $CIO_CONFIG or cio/config.json in the user config directory. Results are written to
+// stdout as JSON; errors are written to stderr as JSON and exit with status 1.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		writeError(os.Stderr, err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+// env bundles the inputs and outputs of a command.
+type env struct {
+	ctx    context.Context
+	flags  *flag.FlagSet
+	stdin  io.Reader
+	stdout io.Writer
+	cfg    func() (customerio.Config, error)
+}
+
+type command struct {
+	usage string
+	run   func(e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"identify":          {"-id ID [-data FILE]", identify},
+	"track":             {"-id ID -name NAME [-data FILE] [-event-id ID] [-timestamp UNIX]", track},
+	"delete":            {"-id ID", deleteCustomer},
+	"merge":             {"-primary TYPE:VALUE -secondary TYPE:VALUE", merge},
+	"add-device":        {"-id ID -device TOKEN -platform PLATFORM [-data FILE]", addDevice},
+	"delete-device":     {"-id ID -device TOKEN", deleteDevice},
+	"trigger-broadcast": {"-broadcast ID [-data FILE]", triggerBroadcast},
+	"send-email":        {"[-data FILE]", sendEmail},
+	"send-push":         {"[-data FILE]", sendPush},
+	"send-sms":          {"[-data FILE]", sendSMS},
+	"send-in-app":       {"[-data FILE]", sendInApp},
+	"send-inbox":        {"[-data FILE]", sendInbox},
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	global := flag.NewFlagSet("cio", flag.ContinueOnError)
+	configPath := global.String("config", "", "path to a JSON config file")
+	region := global.String("region", "", "workspace region, us or eu")
+	baseURL := global.String("url", "", "override the API base URL")
+	global.Usage = func() {
+		out := global.Output()
+		fmt.Fprintln(out, "usage: cio [global flags] <command> [flags]")
+		fmt.Fprintln(out, "\nglobal flags:")
+		global.PrintDefaults()
+		fmt.Fprintln(out, "\ncommands:")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(out, "  %-18s %s\n", name, commands[name].usage)
+		}
+	}
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return flag.ErrHelp
+	}
+
+	name := global.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		global.Usage()
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	e := &env{
+		ctx:    ctx,
+		flags:  flag.NewFlagSet("cio "+name, flag.ContinueOnError),
+		stdin:  stdin,
+		stdout: stdout,
+		cfg: func() (customerio.Config, error) {
+			path := *configPath
+			if path == "" {
+				path = defaultConfigPath()
+			}
+			c, err := loadConfig(path, *configPath != "")
+			if err != nil {
+				return c, err
+			}
+			if *region != "" {
+				c.Region = customerio.Region(*region)
+			}
+			if *baseURL != "" {
+				c.URL = *baseURL
+			}
+			return c, nil
+		},
+	}
+	return cmd.run(e, global.Args()[1:])
+}
+
+func (e *env) track() (*customerio.CustomerIO, error) {
+	c, err := e.cfg()
+	if err != nil {
+		return nil, err
+	}
+	return c.TrackClient()
+}
+
+func (e *env) api() (*customerio.APIClient, error) {
+	c, err := e.cfg()
+	if err != nil {
+		return nil, err
+	}
+	return c.APIClient()
+}
+
+// readJSON decodes the JSON in the file at path, or stdin if path is "-",
+// into v. An empty path leaves v unchanged.
+func (e *env) readJSON(path string, v any) error {
+	var r io.Reader
+	switch path {
+	case "":
+		return nil
+	case "-":
+		r = e.stdin
+	default:
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		r = f
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
+
+func (e *env) writeJSON(v any) error {
+	enc := json.NewEncoder(e.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (e *env) ok() error {
+	return e.writeJSON(map[string]bool{"ok": true})
+}
+
+// writeError reports err as JSON, including the HTTP status of API errors.
+func writeError(w io.Writer, err error) {
+	out := map[string]any{"error": err.Error()}
+	var cerr *customerio.CustomerIOError
+	var terr *customerio.TransactionalError
+	switch {
+	case errors.As(err, &cerr):
+		out["status"] = cerr.StatusCode()
+	case errors.As(err, &terr):
+		out["status"] = terr.StatusCode
+	}
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+func parseIdentifier(s string) (customerio.Identifier, error) {
+	typ, value, ok := strings.Cut(s, ":")
+	if !ok {
+		return customerio.Identifier{}, fmt.Errorf("identifier %q must be TYPE:VALUE, e.g. email:lucy@example.com", s)
+	}
+	return customerio.Identifier{Type: customerio.IdentifierType(typ), Value: value}, nil
+}
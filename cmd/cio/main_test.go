@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setTestEnv(t *testing.T, url string) {
+	t.Setenv("CIO_CONFIG", filepath.Join(t.TempDir(), "missing.json"))
+	t.Setenv("CUSTOMERIO_SITE_ID", "siteid")
+	t.Setenv("CUSTOMERIO_TRACK_API_KEY", "apikey")
+	t.Setenv("CUSTOMERIO_APP_API_KEY", "appkey")
+	t.Setenv("CUSTOMERIO_URL", url)
+}
+
+func TestRunIdentifyFromStdin(t *testing.T) {
+	var gotPath, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		gotPath, gotBody = req.Method+" "+req.URL.Path, string(b)
+	}))
+	defer srv.Close()
+
+	setTestEnv(t, srv.URL)
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"identify", "-id", "5", "-data", "-"},
+		strings.NewReader(`{"plan":"pro"}`), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "PUT /api/v1/customers/5" || gotBody != `{"plan":"pro"}` {
+		t.Errorf("unexpected request %s %s", gotPath, gotBody)
+	}
+	if strings.TrimSpace(out.String()) != "{\n  \"ok\": true\n}" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
+
+func TestRunSendEmailOutputsDeliveryID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/send/email" || req.Header.Get("Authorization") != "Bearer appkey" {
+			t.Errorf("unexpected request %s", req.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"delivery_id":"dlv_1","queued_at":1500000000}`))
+	}))
+	defer srv.Close()
+
+	setTestEnv(t, srv.URL)
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"send-email"},
+		strings.NewReader(`{"transactional_message_id":"3","identifiers":{"id":"5"},"to":"a@example.com"}`), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["delivery_id"] != "dlv_1" {
+		t.Errorf("unexpected output %s", out.String())
+	}
+}
+
+func TestRunTriggerBroadcastOutputsTriggerIDs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/campaigns/7/triggers" {
+			t.Errorf("unexpected path %s", req.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"id":42}`))
+	}))
+	defer srv.Close()
+
+	setTestEnv(t, "")
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"-url", srv.URL, "trigger-broadcast", "-broadcast", "7", "-data", "-"},
+		strings.NewReader(`{"recipients":{"ids":["1","2"]}}`), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"trigger_ids": [`) || !strings.Contains(out.String(), "42") {
+		t.Errorf("unexpected output %s", out.String())
+	}
+}
+
+func TestRunMissingCredentials(t *testing.T) {
+	setTestEnv(t, "")
+	t.Setenv("CUSTOMERIO_SITE_ID", "")
+	err := run(context.Background(), []string{"delete", "-id", "5"}, nil, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "CUSTOMERIO_SITE_ID") {
+		t.Errorf("expected missing credentials error, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Environment variables read by ConfigFromEnv and LoadConfig.
+const (
+	EnvSiteID      = "CUSTOMERIO_SITE_ID"
+	EnvTrackAPIKey = "CUSTOMERIO_TRACK_API_KEY"
+	EnvAppAPIKey   = "CUSTOMERIO_APP_API_KEY"
+	EnvRegion      = "CUSTOMERIO_REGION"
+	EnvURL         = "CUSTOMERIO_URL"
+	EnvUserAgent   = "CUSTOMERIO_USER_AGENT"
+	EnvTimeout     = "CUSTOMERIO_TIMEOUT"
+)
+
+// Config holds the credentials and settings needed to construct clients.
+// Unlike the Option constructors, invalid values are reported as errors by
+// TrackClient and APIClient rather than panics.
+type Config struct {
+	SiteID      string `json:"site_id"`
+	TrackAPIKey string `json:"track_api_key"`
+	AppAPIKey   string `json:"app_api_key"`
+	// Region selects the workspace region; empty means RegionUS.
+	Region Region `json:"region"`
+	// URL overrides the base URL of both APIs; see WithURL.
+	URL       string `json:"url"`
+	UserAgent string `json:"user_agent"`
+	// Timeout overrides DefaultHTTPTimeout. In JSON it is a duration string
+	// such as "10s".
+	Timeout time.Duration `json:"timeout"`
+}
+
+func (c *Config) UnmarshalJSON(b []byte) error {
+	type config Config
+	var r struct {
+		*config
+		Timeout string `json:"timeout"`
+	}
+	r.config = (*config)(c)
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	if r.Timeout != "" {
+		d, err := time.ParseDuration(r.Timeout)
+		if err != nil {
+			return fmt.Errorf("timeout: %w", err)
+		}
+		c.Timeout = d
+	}
+	return nil
+}
+
+// ConfigFromEnv reads a Config from the CUSTOMERIO_* environment variables.
+func ConfigFromEnv() (Config, error) {
+	var c Config
+	err := c.applyEnv(os.LookupEnv)
+	return c, err
+}
+
+// LoadConfig reads a Config from the JSON file at path, with keys site_id,
+// track_api_key, app_api_key, region, url, user_agent and timeout. Any
+// CUSTOMERIO_* environment variables that are set override the file's values.
+func LoadConfig(path string) (Config, error) {
+	var c Config
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, fmt.Errorf("customerio: reading config: %w", err)
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("customerio: parsing config %s: %w", path, err)
+	}
+	err = c.applyEnv(os.LookupEnv)
+	return c, err
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	for env, field := range map[string]*string{
+		EnvSiteID:      &c.SiteID,
+		EnvTrackAPIKey: &c.TrackAPIKey,
+		EnvAppAPIKey:   &c.AppAPIKey,
+		EnvURL:         &c.URL,
+		EnvUserAgent:   &c.UserAgent,
+	} {
+		if v, ok := lookup(env); ok && v != "" {
+			*field = v
+		}
+	}
+	if v, ok := lookup(EnvRegion); ok && v != "" {
+		c.Region = Region(v)
+	}
+	if v, ok := lookup(EnvTimeout); ok && v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("customerio: %s: %w", EnvTimeout, err)
+		}
+		c.Timeout = d
+	}
+	return nil
+}
+
+// options validates the endpoint settings and converts them to Options.
+func (c Config) options() ([]Option, error) {
+	var opts []Option
+	switch c.Region {
+	case "":
+	case RegionUS, RegionEU:
+		opts = append(opts, WithRegion(c.Region))
+	default:
+		return nil, fmt.Errorf("customerio: unknown region %q, want %q or %q", c.Region, RegionUS, RegionEU)
+	}
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			return nil, fmt.Errorf("customerio: invalid url: %w", err)
+		}
+		if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return nil, fmt.Errorf("customerio: invalid url %q: want an absolute http or https URL", c.URL)
+		}
+		opts = append(opts, WithURL(c.URL))
+	}
+	if c.UserAgent != "" {
+		opts = append(opts, WithUserAgent(c.UserAgent))
+	}
+	if c.Timeout < 0 {
+		return nil, fmt.Errorf("customerio: negative timeout %s", c.Timeout)
+	}
+	if c.Timeout > 0 {
+		opts = append(opts, WithHTTPClient(&http.Client{
+			Timeout:   c.Timeout,
+			Transport: newDefaultTransport(),
+		}))
+	}
+	return opts, nil
+}
+
+// TrackClient returns a Track API client for the config, applying opts after
+// the config's own settings.
+func (c Config) TrackClient(opts ...Option) (*CustomerIO, error) {
+	return c.TrackClientCtx(context.Background(), opts...)
+}
+
+// TrackClientCtx is like TrackClient but uses ctx to detect the region for
+// WithAutoRegion.
+func (c Config) TrackClientCtx(ctx context.Context, opts ...Option) (*CustomerIO, error) {
+	opts, provided, errs := c.withOptions(opts)
+	if c.SiteID == "" && !provided {
+		errs = append(errs, fmt.Errorf("customerio: missing site ID (%s)", EnvSiteID))
+	}
+	if c.TrackAPIKey == "" && !provided {
+		errs = append(errs, fmt.Errorf("customerio: missing Track API key (%s)", EnvTrackAPIKey))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	opts, err := detectRegions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newTrackClient(c.SiteID, c.TrackAPIKey, opts), nil
+}
+
+// APIClient returns an App API client for the config, applying opts after
+// the config's own settings.
+func (c Config) APIClient(opts ...Option) (*APIClient, error) {
+	return c.APIClientCtx(context.Background(), opts...)
+}
+
+// APIClientCtx is like APIClient but uses ctx to detect the region for
+// WithAutoRegion.
+func (c Config) APIClientCtx(ctx context.Context, opts ...Option) (*APIClient, error) {
+	opts, provided, errs := c.withOptions(opts)
+	if c.AppAPIKey == "" && !provided {
+		errs = append(errs, fmt.Errorf("customerio: missing App API key (%s)", EnvAppAPIKey))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	opts, err := detectRegions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIClient(c.AppAPIKey, opts), nil
+}
+
+// withOptions returns the config's own options followed by opts, whether
+// they include a credentials provider, and the errors found checking them
+// all together.
+func (c Config) withOptions(opts []Option) (all []Option, credentials bool, errs []error) {
+	base, err := c.options()
+	if err != nil {
+		return nil, false, append(checkOptions(opts).errs, err)
+	}
+	all = append(base, opts...)
+	checked := checkOptions(all)
+	return all, checked.credentials, checked.errs
+}
+
+// NewTrackClientFromEnv prepares a Track API client from the CUSTOMERIO_*
+// environment variables, returning an error if they are missing or invalid.
+func NewTrackClientFromEnv(opts ...Option) (*CustomerIO, error) {
+	c, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return c.TrackClient(opts...)
+}
+
+// NewAPIClientFromEnv prepares an App API client from the CUSTOMERIO_*
+// environment variables, returning an error if they are missing or invalid.
+func NewAPIClientFromEnv(opts ...Option) (*APIClient, error) {
+	c, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return c.APIClient(opts...)
+}
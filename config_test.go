@@ -0,0 +1,95 @@
+package customerio_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestNewTrackClientFromEnv(t *testing.T) {
+	t.Setenv(customerio.EnvSiteID, "site")
+	t.Setenv(customerio.EnvTrackAPIKey, "key")
+	t.Setenv(customerio.EnvRegion, "eu")
+	t.Setenv(customerio.EnvUserAgent, "my-service")
+	t.Setenv(customerio.EnvTimeout, "5s")
+
+	client, err := customerio.NewTrackClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.URL != customerio.RegionEU.TrackURL() {
+		t.Errorf("wrong url %s", client.URL)
+	}
+	if client.UserAgent != "my-service" {
+		t.Errorf("wrong user agent %s", client.UserAgent)
+	}
+	hc, ok := client.Client.(*http.Client)
+	if !ok || hc.Timeout != 5*time.Second {
+		t.Errorf("wrong http client %#v", client.Client)
+	}
+}
+
+func TestNewAPIClientFromEnvErrors(t *testing.T) {
+	t.Setenv(customerio.EnvAppAPIKey, "")
+	t.Setenv(customerio.EnvRegion, "mars")
+	t.Setenv(customerio.EnvURL, "")
+	t.Setenv(customerio.EnvTimeout, "")
+
+	_, err := customerio.NewAPIClientFromEnv()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{customerio.EnvAppAPIKey, `unknown region "mars"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error %q", want, err)
+		}
+	}
+
+	t.Setenv(customerio.EnvTimeout, "soon")
+	if _, err := customerio.NewAPIClientFromEnv(); err == nil || !strings.Contains(err.Error(), customerio.EnvTimeout) {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cio.json")
+	if err := os.WriteFile(path, []byte(`{"app_api_key":"file-key","url":"http://localhost:8080","timeout":"2s"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(customerio.EnvAppAPIKey, "env-key")
+	t.Setenv(customerio.EnvURL, "")
+	t.Setenv(customerio.EnvRegion, "")
+	t.Setenv(customerio.EnvTimeout, "")
+
+	cfg, err := customerio.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AppAPIKey != "env-key" || cfg.URL != "http://localhost:8080" || cfg.Timeout != 2*time.Second {
+		t.Errorf("unexpected config %#v", cfg)
+	}
+
+	api, err := cfg.APIClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.URL != "http://localhost:8080" || api.Key != "env-key" {
+		t.Errorf("unexpected client %#v", api)
+	}
+
+	if _, err := customerio.LoadConfig(filepath.Join(dir, "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+
+	bad := customerio.Config{AppAPIKey: "k", URL: "not a url"}
+	if _, err := bad.APIClient(); err == nil {
+		t.Error("expected invalid url error")
+	}
+}
@@ -0,0 +1,139 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Credentials holds the secrets used to authenticate requests. Track clients
+// use SiteID and TrackAPIKey; App API clients use AppAPIKey.
+type Credentials struct {
+	SiteID      string `json:"site_id"`
+	TrackAPIKey string `json:"track_api_key"`
+	AppAPIKey   string `json:"app_api_key"`
+}
+
+// CredentialsProvider supplies the credentials for each request, allowing
+// keys to be rotated without rebuilding clients. Implementations must be safe
+// for concurrent use.
+//
+// When a request is rejected with 401 Unauthorized the client asks the
+// provider again, and retries the request once if the credentials changed.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// WithCredentialsProvider makes clients fetch their credentials from p on
+// every request instead of using the values given to the constructor.
+func WithCredentialsProvider(p CredentialsProvider) Option {
+	if p == nil {
+		return option{err: errors.New("customerio: WithCredentialsProvider called with nil CredentialsProvider")}
+	}
+	return option{
+		api: func(a *APIClient) {
+			a.creds = p
+		},
+		track: func(c *CustomerIO) {
+			c.creds = p
+		},
+		credentials: true,
+	}
+}
+
+type staticCredentials Credentials
+
+func (s staticCredentials) Credentials(context.Context) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+// StaticCredentials returns a provider that always supplies creds.
+func StaticCredentials(creds Credentials) CredentialsProvider {
+	return staticCredentials(creds)
+}
+
+type envCredentials struct{}
+
+func (envCredentials) Credentials(context.Context) (Credentials, error) {
+	return Credentials{
+		SiteID:      os.Getenv(EnvSiteID),
+		TrackAPIKey: os.Getenv(EnvTrackAPIKey),
+		AppAPIKey:   os.Getenv(EnvAppAPIKey),
+	}, nil
+}
+
+// EnvCredentials returns a provider that reads the CUSTOMERIO_SITE_ID,
+// CUSTOMERIO_TRACK_API_KEY and CUSTOMERIO_APP_API_KEY environment variables
+// on every request.
+func EnvCredentials() CredentialsProvider {
+	return envCredentials{}
+}
+
+type fileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	creds   Credentials
+}
+
+// FileCredentials returns a provider that reads credentials from the JSON
+// file at path, using the site_id, track_api_key and app_api_key keys of the
+// LoadConfig format. The file is re-read whenever its modification time or
+// size changes, so a secret mounted by an orchestrator can be rotated in
+// place.
+func FileCredentials(path string) CredentialsProvider {
+	return &fileCredentials{path: path}
+}
+
+func (f *fileCredentials) Credentials(context.Context) (Credentials, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("customerio: reading credentials: %w", err)
+	}
+	if info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.creds, nil
+	}
+
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("customerio: reading credentials: %w", err)
+	}
+	var creds Credentials
+	if err := json.Unmarshal(b, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("customerio: parsing credentials %s: %w", f.path, err)
+	}
+	f.creds, f.modTime, f.size = creds, info.ModTime(), info.Size()
+	return creds, nil
+}
+
+// doAuthenticated calls do with the credentials from p, or with static when p
+// is nil. If the request is rejected with 401 and p then returns different
+// credentials, the request is retried once with them.
+func doAuthenticated(ctx context.Context, p CredentialsProvider, static Credentials, do func(Credentials) ([]byte, int, error)) ([]byte, int, error) {
+	if p == nil {
+		return do(static)
+	}
+	creds, err := p.Credentials(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	body, statusCode, err := do(creds)
+	if err != nil || statusCode != http.StatusUnauthorized {
+		return body, statusCode, err
+	}
+	fresh, ferr := p.Credentials(ctx)
+	if ferr != nil || fresh == creds {
+		return body, statusCode, err
+	}
+	return do(fresh)
+}
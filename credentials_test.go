@@ -0,0 +1,170 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+// rotatingCredentials returns each of its credentials in turn, then keeps
+// returning the last one.
+type rotatingCredentials struct {
+	mu    sync.Mutex
+	creds []customerio.Credentials
+	calls int
+}
+
+func (r *rotatingCredentials) Credentials(context.Context) (customerio.Credentials, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := min(r.calls, len(r.creds)-1)
+	r.calls++
+	return r.creds[i], nil
+}
+
+// authServer accepts only requests carrying want as their Authorization
+// header, and counts every request it receives.
+func authServer(t *testing.T, want string) (string, *int) {
+	t.Helper()
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		if req.Header.Get("Authorization") != want {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, &requests
+}
+
+func TestAPIClientRetriesWithRotatedCredentials(t *testing.T) {
+	url, requests := authServer(t, "Bearer new")
+	provider := &rotatingCredentials{creds: []customerio.Credentials{{AppAPIKey: "old"}, {AppAPIKey: "new"}}}
+	api := customerio.NewAPIClient("", customerio.WithURL(url), customerio.WithCredentialsProvider(provider))
+
+	if _, err := api.GetCampaign(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if *requests != 2 {
+		t.Errorf("expected 2 requests, got %d", *requests)
+	}
+}
+
+func TestTrackClientRetriesWithRotatedCredentials(t *testing.T) {
+	basic := func(site, key string) string {
+		return "Basic " + base64.URLEncoding.EncodeToString([]byte(site+":"+key))
+	}
+	url, requests := authServer(t, basic("site", "new"))
+	provider := &rotatingCredentials{creds: []customerio.Credentials{
+		{SiteID: "site", TrackAPIKey: "old"},
+		{SiteID: "site", TrackAPIKey: "new"},
+	}}
+	client := customerio.NewTrackClient("", "", customerio.WithURL(url), customerio.WithCredentialsProvider(provider))
+
+	if err := client.Identify("1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if *requests != 2 {
+		t.Errorf("expected 2 requests, got %d", *requests)
+	}
+}
+
+func TestUnchangedCredentialsAreNotRetried(t *testing.T) {
+	url, requests := authServer(t, "Bearer other")
+	api := customerio.NewAPIClient("", customerio.WithURL(url),
+		customerio.WithCredentialsProvider(customerio.StaticCredentials(customerio.Credentials{AppAPIKey: "key"})))
+
+	_, err := api.GetCampaign(context.Background(), 1)
+	if cerr, ok := err.(*customerio.CustomerIOError); !ok || cerr.StatusCode() != http.StatusUnauthorized {
+		t.Fatalf("expected 401 error, got %v", err)
+	}
+	if *requests != 1 {
+		t.Errorf("expected 1 request, got %d", *requests)
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv(customerio.EnvSiteID, "site")
+	t.Setenv(customerio.EnvTrackAPIKey, "track")
+	t.Setenv(customerio.EnvAppAPIKey, "app")
+	provider := customerio.EnvCredentials()
+
+	got, err := provider.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (customerio.Credentials{SiteID: "site", TrackAPIKey: "track", AppAPIKey: "app"}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	t.Setenv(customerio.EnvAppAPIKey, "rotated")
+	got, _ = provider.Credentials(context.Background())
+	if got.AppAPIKey != "rotated" {
+		t.Errorf("expected reloaded app key, got %q", got.AppAPIKey)
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	write := func(content string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	provider := customerio.FileCredentials(path)
+
+	if _, err := provider.Credentials(context.Background()); err == nil {
+		t.Error("expected error for missing file")
+	}
+
+	start := time.Now().Add(-time.Hour)
+	write(`{"site_id": "site", "track_api_key": "key1", "region": "eu"}`, start)
+	got, err := provider.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SiteID != "site" || got.TrackAPIKey != "key1" {
+		t.Errorf("unexpected credentials %+v", got)
+	}
+
+	write(`{"site_id": "site", "track_api_key": "key2", "region": "eu"}`, start.Add(time.Minute))
+	got, err = provider.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TrackAPIKey != "key2" {
+		t.Errorf("expected reloaded key, got %q", got.TrackAPIKey)
+	}
+
+	write(`{`, start.Add(2*time.Minute))
+	if _, err := provider.Credentials(context.Background()); err == nil {
+		t.Error("expected error for invalid file")
+	}
+}
+
+func TestValidatingConstructorsAcceptCredentialsProvider(t *testing.T) {
+	provider := customerio.WithCredentialsProvider(customerio.EnvCredentials())
+	if _, err := customerio.NewTrackClientE("", "", provider); err != nil {
+		t.Errorf("unexpected track error: %v", err)
+	}
+	if _, err := customerio.NewAPIClientE("", provider); err != nil {
+		t.Errorf("unexpected api error: %v", err)
+	}
+	if _, err := customerio.NewAPIClientE("key", customerio.WithCredentialsProvider(nil)); err == nil {
+		t.Error("expected error for nil provider")
+	}
+}
@@ -15,6 +15,7 @@ const DefaultUserAgent = "Customer.io Go Client/" + Version
 type CustomerIO struct {
 	siteID    string
 	apiKey    string
+	creds     CredentialsProvider
 	URL       string
 	UserAgent string
 	Client    HTTPClient
@@ -57,7 +58,51 @@ func (e ParamError) Error() string { return e.Param + ": missing" }
 
 // NewTrackClient prepares a client for use with the Customer.io track API, see: https://customer.io/docs/api/#apitrackintroduction
 // using a Tracking Site ID and API Key pair from https://fly.customer.io/settings/api_credentials
+//
+// NewTrackClient panics if any option is invalid; use NewTrackClientE when
+// options come from runtime configuration.
 func NewTrackClient(siteID, apiKey string, opts ...Option) *CustomerIO {
+	if checked := checkOptions(opts); len(checked.errs) > 0 {
+		panic(checked.errs[0].Error())
+	} else if checked.autoRegion {
+		panic("customerio: WithAutoRegion requires NewTrackClientCtx or NewTrackClientE")
+	}
+	return newTrackClient(siteID, apiKey, opts)
+}
+
+// NewTrackClientE is like NewTrackClient but returns an error instead of
+// panicking. It also requires non-empty credentials and an https base URL,
+// unless WithInsecureURL is given. All problems are reported together.
+func NewTrackClientE(siteID, apiKey string, opts ...Option) (*CustomerIO, error) {
+	return NewTrackClientCtx(context.Background(), siteID, apiKey, opts...)
+}
+
+// NewTrackClientCtx is like NewTrackClientE but uses ctx to detect the region
+// for WithAutoRegion.
+func NewTrackClientCtx(ctx context.Context, siteID, apiKey string, opts ...Option) (*CustomerIO, error) {
+	checked := checkOptions(opts)
+	errs := checked.errs
+	if siteID == "" && !checked.credentials {
+		errs = append(errs, ParamError{Param: "siteID"})
+	}
+	if apiKey == "" && !checked.credentials {
+		errs = append(errs, ParamError{Param: "apiKey"})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	opts, err := detectRegions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	c := newTrackClient(siteID, apiKey, opts)
+	if err := checkBaseURL(c.URL, checked.insecure); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func newTrackClient(siteID, apiKey string, opts []Option) *CustomerIO {
 	c := &CustomerIO{
 		siteID:    siteID,
 		apiKey:    apiKey,
@@ -140,27 +185,36 @@ func (c *CustomerIO) DeleteCtx(ctx context.Context, customerID string) error {
 	return c.request(ctx, "DELETE", c.URL+formatPath("/api/v1/customers/%s", customerID), nil)
 }
 
-func (c *CustomerIO) auth() string {
-	return base64.URLEncoding.EncodeToString(fmt.Appendf(nil, "%v:%v", c.siteID, c.apiKey))
+func (c *CustomerIO) auth(creds Credentials) string {
+	return base64.URLEncoding.EncodeToString(fmt.Appendf(nil, "%v:%v", creds.SiteID, creds.TrackAPIKey))
 }
 
 func (c *CustomerIO) request(ctx context.Context, method, url string, body any) error {
-	respBody, statusCode, err := doHTTP(ctx, c.Client, method, url, c.UserAgent, body, func(req *http.Request) {
-		req.Header.Set("Authorization", fmt.Sprintf("Basic %v", c.auth()))
+	_, err := c.requestBody(ctx, method, url, body)
+	return err
+}
+
+// requestBody is like request but also returns the response body.
+func (c *CustomerIO) requestBody(ctx context.Context, method, url string, body any) ([]byte, error) {
+	static := Credentials{SiteID: c.siteID, TrackAPIKey: c.apiKey}
+	respBody, statusCode, err := doAuthenticated(ctx, c.creds, static, func(creds Credentials) ([]byte, int, error) {
+		return doHTTP(ctx, c.Client, method, url, c.UserAgent, body, func(req *http.Request) {
+			req.Header.Set("Authorization", fmt.Sprintf("Basic %v", c.auth(creds)))
+		})
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if statusCode != http.StatusOK {
-		return &CustomerIOError{
+		return nil, &CustomerIOError{
 			status: statusCode,
 			url:    url,
 			body:   respBody,
 		}
 	}
 
-	return nil
+	return respBody, nil
 }
 
 type IdentifierType string
@@ -95,6 +95,25 @@ func trackServer(t *testing.T) (*customerio.CustomerIO, *requestRecord) {
 	return client, rec
 }
 
+// appServer creates a per-test App API server that expects a single kind of
+// request, identified by method and request URI, and replies with response.
+// It returns an APIClient pointed at the server.
+func appServer(t *testing.T, method, requestURI, response string) *customerio.APIClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != method || req.RequestURI != requestURI {
+			t.Errorf("expected %s %s got %s %s", method, requestURI, req.Method, req.RequestURI)
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer myKey" {
+			t.Errorf("unexpected Authorization %q", got)
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+
+	return customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+}
+
 // assertRequest verifies that the recorded request matches the expected
 // method, path, and body. The body parameter may be nil (for no-body
 // requests like DELETE), a map/struct (compared via JSON marshaling),
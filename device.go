@@ -2,9 +2,139 @@ package customerio
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Platform is the operating system of a push notification device.
+type Platform string
+
+// Platforms Customer.io accepts. The constants are untyped, so they can be
+// passed as the platform string of NewDevice and AddDevice as well as used
+// as a Platform.
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+)
+
+var (
+	// ErrUnknownPlatform is returned for a device platform other than
+	// PlatformIOS or PlatformAndroid.
+	ErrUnknownPlatform = errors.New("unknown device platform")
+	// ErrInvalidDeviceToken is returned for a device token that cannot be a
+	// push token for its platform.
+	ErrInvalidDeviceToken = errors.New("invalid device token")
+)
+
+// maxDeviceTokenLength bounds device tokens well above the length of any APNs
+// or FCM token.
+const maxDeviceTokenLength = 4096
+
+// Validate reports whether p is a platform Customer.io accepts.
+func (p Platform) Validate() error {
+	switch p {
+	case PlatformIOS, PlatformAndroid:
+		return nil
+	}
+	return fmt.Errorf("%w %q", ErrUnknownPlatform, string(p))
+}
+
+// ValidateToken reports whether token has the format of a push token for p.
+// Tokens are limited to the characters of APNs and FCM tokens and a generous
+// length. Token lengths are otherwise not checked, since neither service
+// guarantees them.
+func (p Platform) ValidateToken(token string) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if len(token) > maxDeviceTokenLength {
+		return fmt.Errorf("%w: %d characters exceeds %d", ErrInvalidDeviceToken, len(token), maxDeviceTokenLength)
+	}
+	for _, r := range token {
+		switch {
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r == '-', r == '_', r == ':', r == '.':
+		default:
+			return fmt.Errorf("%w: unexpected character %q", ErrInvalidDeviceToken, r)
+		}
+	}
+	return nil
+}
+
+// Well-known device attribute names understood by Customer.io.
+const (
+	DeviceAttributeAppVersion  = "app_version"
+	DeviceAttributeOSVersion   = "device_os"
+	DeviceAttributeModel       = "device_model"
+	DeviceAttributePushEnabled = "push_enabled"
+)
+
+// DeviceAttributes holds the well-known attributes of a device alongside any
+// custom ones. Use Map to pass them to AddDeviceCtx or NewDevice.
+type DeviceAttributes struct {
+	AppVersion  string
+	OSVersion   string
+	DeviceModel string
+	// PushEnabled reports whether the user allows notifications; nil leaves
+	// the attribute unset.
+	PushEnabled *bool
+	// Custom holds any other attributes. Well-known fields that are set take
+	// precedence over custom attributes of the same name.
+	Custom map[string]any
+}
+
+// Map returns the attributes as a device data map.
+func (a DeviceAttributes) Map() map[string]any {
+	m := make(map[string]any, len(a.Custom)+4)
+	for k, v := range a.Custom {
+		m[k] = v
+	}
+	if a.AppVersion != "" {
+		m[DeviceAttributeAppVersion] = a.AppVersion
+	}
+	if a.OSVersion != "" {
+		m[DeviceAttributeOSVersion] = a.OSVersion
+	}
+	if a.DeviceModel != "" {
+		m[DeviceAttributeModel] = a.DeviceModel
+	}
+	if a.PushEnabled != nil {
+		// Customer.io's SDKs report push_enabled as a string.
+		m[DeviceAttributePushEnabled] = strconv.FormatBool(*a.PushEnabled)
+	}
+	return m
+}
+
+// deviceAttributes splits a device data map into well-known and custom
+// attributes.
+func deviceAttributes(m map[string]any) DeviceAttributes {
+	var a DeviceAttributes
+	for k, v := range m {
+		s, isString := v.(string)
+		switch {
+		case k == DeviceAttributeAppVersion && isString:
+			a.AppVersion = s
+		case k == DeviceAttributeOSVersion && isString:
+			a.OSVersion = s
+		case k == DeviceAttributeModel && isString:
+			a.DeviceModel = s
+		case k == DeviceAttributePushEnabled && (isString || v == true || v == false):
+			enabled := v == true || s == "true"
+			a.PushEnabled = &enabled
+		default:
+			if a.Custom == nil {
+				a.Custom = make(map[string]any)
+			}
+			a.Custom[k] = v
+		}
+	}
+	return a
+}
+
 type deviceV1 struct {
 	ID         string         `json:"id"`
 	Platform   string         `json:"platform"`
@@ -14,12 +144,64 @@ type deviceV1 struct {
 
 // Device identifies a push notification device for transactional sends.
 type Device struct {
-	Token      string         `json:"token"`
-	Platform   string         `json:"platform"`
+	Token    string `json:"token"`
+	Platform string `json:"platform"`
+	// LastUsed is when the device was last used, as a Unix timestamp. Use
+	// LastUsedTime and SetLastUsed to work with it as a time.Time.
 	LastUsed   string         `json:"last_used,omitempty"`
 	Attributes map[string]any `json:"attributes"`
 }
 
+// LastUsedTime returns LastUsed as a time, or the zero time if it is unset.
+func (d *Device) LastUsedTime() (time.Time, error) {
+	if d.LastUsed == "" {
+		return time.Time{}, nil
+	}
+	return lastUsedTime(d.LastUsed)
+}
+
+// SetLastUsed sets LastUsed to t, or clears it if t is the zero time.
+func (d *Device) SetLastUsed(t time.Time) {
+	d.LastUsed = ""
+	if !t.IsZero() {
+		d.LastUsed = strconv.FormatInt(t.Unix(), 10)
+	}
+}
+
+// WellKnownAttributes returns the device's attributes split into well-known and
+// custom ones.
+func (d *Device) WellKnownAttributes() DeviceAttributes {
+	return deviceAttributes(d.Attributes)
+}
+
+// lastUsedTime converts a last_used value, a time.Time or a Unix timestamp
+// as a number or numeric string, to a time.
+func lastUsedTime(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case int:
+		return time.Unix(int64(v), 0), nil
+	case int64:
+		return time.Unix(v, 0), nil
+	case float64:
+		return time.Unix(int64(v), 0), nil
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("last_used: %w", err)
+		}
+		return time.Unix(n, 0), nil
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("last_used: %q is not a Unix timestamp", v)
+		}
+		return time.Unix(n, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("last_used: unsupported type %T", v)
+}
+
 func newDeviceV1(deviceID, platform string, data map[string]any) (*deviceV1, error) {
 	if deviceID == "" {
 		return nil, ParamError{Param: "deviceID"}
@@ -27,6 +209,9 @@ func newDeviceV1(deviceID, platform string, data map[string]any) (*deviceV1, err
 	if platform == "" {
 		return nil, ParamError{Param: "platform"}
 	}
+	if err := Platform(platform).ValidateToken(deviceID); err != nil {
+		return nil, err
+	}
 	d := &deviceV1{
 		ID:       deviceID,
 		Platform: platform,
@@ -38,7 +223,11 @@ func newDeviceV1(deviceID, platform string, data map[string]any) (*deviceV1, err
 
 	for k, v := range data {
 		if k == "last_used" {
-			d.LastUsed = fmt.Sprintf("%v", v)
+			t, err := lastUsedTime(v)
+			if err != nil {
+				return nil, err
+			}
+			d.LastUsed = strconv.FormatInt(t.Unix(), 10)
 			continue
 		}
 		d.Attributes[k] = v
@@ -48,6 +237,8 @@ func newDeviceV1(deviceID, platform string, data map[string]any) (*deviceV1, err
 }
 
 // NewDevice prepares a push notification device for transactional sends.
+// data may hold last_used, as a time.Time or Unix timestamp, and the device
+// attributes, such as those returned by DeviceAttributes.Map.
 func NewDevice(deviceID, platform string, data map[string]any) (*Device, error) {
 	d, err := newDeviceV1(deviceID, platform, data)
 	if err != nil {
@@ -66,7 +257,8 @@ func (c *CustomerIO) Delete(customerID string) error {
 	return c.DeleteCtx(context.Background(), customerID)
 }
 
-// AddDeviceCtx adds a device for a customer
+// AddDeviceCtx adds a device for a customer. The token format is checked
+// against the platform before the request is sent.
 func (c *CustomerIO) AddDeviceCtx(ctx context.Context, customerID string, deviceID string, platform string, data map[string]any) error {
 	if customerID == "" {
 		return ParamError{Param: "customerID"}
@@ -0,0 +1,179 @@
+package customerio_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+const (
+	apnsToken = "740f4707bebcf74f9b7c25d48e3358945f6aa01da5ddb387462c7eaf61bb78ad"
+	fcmToken  = "dQw4w9WgXcQ:APA91bHun4MxP5egoKMwt2KZFBaFUH-1RYqx_B2hNbv1zLhkXmJ8Cmd2"
+)
+
+func TestPlatformValidateToken(t *testing.T) {
+	cases := []struct {
+		platform customerio.Platform
+		token    string
+		err      error
+	}{
+		{customerio.PlatformIOS, apnsToken, nil},
+		{customerio.PlatformIOS, strings.ToUpper(apnsToken), nil},
+		{customerio.PlatformIOS, fcmToken, nil},
+		{customerio.PlatformAndroid, fcmToken, nil},
+		{customerio.PlatformIOS, "device-id", nil},
+		{customerio.PlatformIOS, apnsToken[:40], nil},
+		{customerio.PlatformIOS, apnsToken[:63], nil},
+		{customerio.PlatformIOS, apnsToken + apnsToken, nil},
+		{customerio.PlatformAndroid, apnsToken, nil},
+		{customerio.PlatformAndroid, "has space", customerio.ErrInvalidDeviceToken},
+		{customerio.PlatformIOS, "<" + apnsToken + ">", customerio.ErrInvalidDeviceToken},
+		{customerio.PlatformAndroid, strings.Repeat("x", 4097), customerio.ErrInvalidDeviceToken},
+		{"windows", fcmToken, customerio.ErrUnknownPlatform},
+	}
+	for _, c := range cases {
+		err := c.platform.ValidateToken(c.token)
+		if !errors.Is(err, c.err) || (c.err == nil) != (err == nil) {
+			t.Errorf("%s %.20q: got %v, want %v", c.platform, c.token, err, c.err)
+		}
+	}
+}
+
+func TestAddDeviceRejectsInvalidToken(t *testing.T) {
+	client, _ := trackServer(t)
+
+	if err := client.AddDevice("1", "bad token", customerio.PlatformIOS, nil); !errors.Is(err, customerio.ErrInvalidDeviceToken) {
+		t.Errorf("expected ErrInvalidDeviceToken, got %v", err)
+	}
+	if err := client.AddDevice("1", fcmToken, "web", nil); !errors.Is(err, customerio.ErrUnknownPlatform) {
+		t.Errorf("expected ErrUnknownPlatform, got %v", err)
+	}
+}
+
+func TestAddDeviceLastUsedTime(t *testing.T) {
+	client, rec := trackServer(t)
+
+	body := map[string]map[string]any{
+		"device": {
+			"id":         apnsToken,
+			"platform":   "ios",
+			"last_used":  "1606511962",
+			"attributes": map[string]any{},
+		},
+	}
+	runCases(t, rec,
+		[]testCase{{"1", "PUT", "/api/v1/customers/1/devices", body}},
+		func(c testCase) error {
+			return client.AddDevice(c.id, apnsToken, customerio.PlatformIOS, map[string]any{
+				"last_used": time.Unix(1606511962, 0),
+			})
+		})
+}
+
+func TestAddDeviceRejectsInvalidLastUsed(t *testing.T) {
+	client, _ := trackServer(t)
+
+	for _, v := range []any{"yesterday", true} {
+		if err := client.AddDevice("1", apnsToken, customerio.PlatformIOS, map[string]any{"last_used": v}); err == nil {
+			t.Errorf("expected an error for last_used %v", v)
+		}
+	}
+}
+
+func TestDeviceLastUsed(t *testing.T) {
+	lastUsed := time.Unix(1606511962, 0)
+	for _, v := range []any{lastUsed, 1606511962, int64(1606511962), float64(1606511962), "1606511962"} {
+		d, err := customerio.NewDevice(fcmToken, customerio.PlatformAndroid, map[string]any{"last_used": v})
+		if err != nil {
+			t.Fatalf("%T: %v", v, err)
+		}
+		if d.LastUsed != "1606511962" {
+			t.Errorf("%T: got last_used %q", v, d.LastUsed)
+		}
+		if got, err := d.LastUsedTime(); err != nil || !got.Equal(lastUsed) {
+			t.Errorf("%T: got %v, %v, want %v", v, got, err, lastUsed)
+		}
+	}
+	if _, err := customerio.NewDevice(fcmToken, customerio.PlatformAndroid, map[string]any{"last_used": "yesterday"}); err == nil {
+		t.Error("expected an error for a non-numeric last_used")
+	}
+
+	d := &customerio.Device{Token: fcmToken, Platform: customerio.PlatformAndroid}
+	if got, err := d.LastUsedTime(); err != nil || !got.IsZero() {
+		t.Errorf("expected the zero time for an unset LastUsed, got %v, %v", got, err)
+	}
+	d.SetLastUsed(lastUsed)
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(b, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload["last_used"] != "1606511962" {
+		t.Errorf("expected last_used to be a Unix timestamp, got %s", b)
+	}
+
+	d.SetLastUsed(time.Time{})
+	if b, err = json.Marshal(d); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "last_used") {
+		t.Errorf("cleared LastUsed should be omitted, got %s", b)
+	}
+	d.LastUsed = "yesterday"
+	if _, err := d.LastUsedTime(); err == nil {
+		t.Error("expected an error for a non-numeric LastUsed")
+	}
+}
+
+func TestDeviceAttributes(t *testing.T) {
+	enabled := true
+	attrs := customerio.DeviceAttributes{
+		AppVersion:  "2.1.0",
+		OSVersion:   "17.4",
+		DeviceModel: "iPhone15,2",
+		PushEnabled: &enabled,
+		Custom:      map[string]any{"theme": "dark"},
+	}
+	want := map[string]any{
+		"app_version":  "2.1.0",
+		"device_os":    "17.4",
+		"device_model": "iPhone15,2",
+		"push_enabled": "true",
+		"theme":        "dark",
+	}
+	if got := attrs.Map(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Map: got %v, want %v", got, want)
+	}
+
+	d, err := customerio.NewDevice(apnsToken, customerio.PlatformIOS, attrs.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.WellKnownAttributes(); !reflect.DeepEqual(got, attrs) {
+		t.Errorf("WellKnownAttributes: got %+v, want %+v", got, attrs)
+	}
+}
+
+func TestSendPushValidatesDevice(t *testing.T) {
+	req := &customerio.SendPushRequest{
+		TransactionalMessageID: "1",
+		Identifiers:            map[string]string{"id": "1"},
+		Device:                 &customerio.Device{Token: "bad token", Platform: customerio.PlatformIOS},
+	}
+	var fe customerio.FieldError
+	if err := req.Validate(); !errors.As(err, &fe) || fe.Field != "custom_device.token" {
+		t.Errorf("expected a custom_device.token error, got %v", err)
+	}
+	req.Device.Token = apnsToken
+	if err := req.Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,351 @@
+package customerio
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Export describes an asynchronous customer or delivery export.
+type Export struct {
+	ID          int    `json:"id"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	// Status is "pending" until the export finishes, then "done" or "failed".
+	Status string `json:"status"`
+	Failed bool   `json:"failed"`
+	// Total is the number of rows in the export.
+	Total     int       `json:"total"`
+	Downloads int       `json:"downloads"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (e *Export) UnmarshalJSON(b []byte) error {
+	type export Export
+	var r struct {
+		*export
+		CreatedAt int64 `json:"created_at"`
+		UpdatedAt int64 `json:"updated_at"`
+	}
+	r.export = (*export)(e)
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	e.CreatedAt = unixTime(r.CreatedAt)
+	e.UpdatedAt = unixTime(r.UpdatedAt)
+	return nil
+}
+
+// Done reports whether the export has finished, successfully or not.
+func (e *Export) Done() bool {
+	return e.Failed || e.Status == "done" || e.Status == "failed"
+}
+
+// ErrExportFailed is returned by WaitForExport when Customer.io reports that
+// the export failed.
+var ErrExportFailed = errors.New("export failed")
+
+// ErrNoDeliverySelector is returned by ExportDeliveries when none of
+// CampaignID, BroadcastID, ActionID or TransactionalMessageID is set.
+var ErrNoDeliverySelector = errors.New("delivery export needs a campaign, broadcast, action or transactional message ID")
+
+// DeliveryExportRequest selects the deliveries to export. At least one of
+// CampaignID, BroadcastID, ActionID or TransactionalMessageID is required.
+type DeliveryExportRequest struct {
+	CampaignID             int
+	BroadcastID            int
+	ActionID               int
+	TransactionalMessageID int
+	// Metric restricts the export to deliveries that reached a state, e.g. "bounced".
+	Metric string
+	Start  time.Time
+	End    time.Time
+	// Drafts includes undelivered drafts in the export.
+	Drafts bool
+}
+
+func (r DeliveryExportRequest) payload() map[string]any {
+	p := map[string]any{}
+	if r.CampaignID > 0 {
+		p["campaign_id"] = r.CampaignID
+	}
+	if r.BroadcastID > 0 {
+		p["newsletter_id"] = r.BroadcastID
+	}
+	if r.ActionID > 0 {
+		p["action_id"] = r.ActionID
+	}
+	if r.TransactionalMessageID > 0 {
+		p["transactional_message_id"] = r.TransactionalMessageID
+	}
+	if r.Metric != "" {
+		p["metric"] = r.Metric
+	}
+	if !r.Start.IsZero() {
+		p["start"] = r.Start.Unix()
+	}
+	if !r.End.IsZero() {
+		p["end"] = r.End.Unix()
+	}
+	if r.Drafts {
+		p["drafts"] = true
+	}
+	return p
+}
+
+// ExportCustomers starts an export of the customers matching filters, a
+// Customer.io audience filter such as {"segment": {"id": 4}}.
+// See https://docs.customer.io/api/app/#operation/exportPeopleData
+func (c *APIClient) ExportCustomers(ctx context.Context, filters map[string]any) (*Export, error) {
+	if len(filters) == 0 {
+		return nil, ParamError{Param: "filters"}
+	}
+	return c.startExport(ctx, "/v1/exports/customers", map[string]any{"filters": filters})
+}
+
+// ExportDeliveries starts an export of the deliveries selected by req.
+// See https://docs.customer.io/api/app/#operation/exportDeliveriesData
+func (c *APIClient) ExportDeliveries(ctx context.Context, req DeliveryExportRequest) (*Export, error) {
+	if req.CampaignID <= 0 && req.BroadcastID <= 0 && req.ActionID <= 0 && req.TransactionalMessageID <= 0 {
+		return nil, ErrNoDeliverySelector
+	}
+	return c.startExport(ctx, "/v1/exports/deliveries", req.payload())
+}
+
+func (c *APIClient) startExport(ctx context.Context, requestPath string, body any) (*Export, error) {
+	var resp struct {
+		Export Export `json:"export"`
+	}
+	if err := c.requestJSON(ctx, "POST", requestPath, body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Export, nil
+}
+
+// GetExport returns the status of an export.
+// See https://docs.customer.io/api/app/#operation/getExport
+func (c *APIClient) GetExport(ctx context.Context, exportID int) (*Export, error) {
+	if exportID <= 0 {
+		return nil, ParamError{Param: "exportID"}
+	}
+
+	var resp struct {
+		Export Export `json:"export"`
+	}
+	if err := c.requestJSON(ctx, "GET", formatPath("/v1/exports/%d", exportID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Export, nil
+}
+
+// WaitForExport polls GetExport with exponential backoff until the export is
+// done or ctx is done, retrying rate limiting, server and network errors as
+// WaitForBroadcastTrigger does. It returns an error wrapping ErrExportFailed, along
+// with the export, if the export failed.
+func (c *APIClient) WaitForExport(ctx context.Context, exportID int) (*Export, error) {
+	var export *Export
+	err := poll(ctx, func() (bool, error) {
+		e, err := c.GetExport(ctx, exportID)
+		if err != nil {
+			return false, err
+		}
+		export = e
+		return e.Done(), nil
+	})
+	if err == nil && (export.Failed || export.Status == "failed") {
+		err = fmt.Errorf("%w: export %d", ErrExportFailed, exportID)
+	}
+	return export, err
+}
+
+// DownloadExport streams the CSV file of a finished export. The caller must
+// close the returned reader. The file is fetched from the signed URL
+// Customer.io returns, without buffering it in memory.
+//
+// The client's timeout covers reading the whole download; use WithHTTPClient
+// with a longer timeout, or none and a ctx deadline, for large exports.
+// See https://docs.customer.io/api/app/#operation/downloadExport
+func (c *APIClient) DownloadExport(ctx context.Context, exportID int) (io.ReadCloser, error) {
+	if exportID <= 0 {
+		return nil, ParamError{Param: "exportID"}
+	}
+
+	var link struct {
+		URL string `json:"url"`
+	}
+	if err := c.requestJSON(ctx, "GET", formatPath("/v1/exports/%d/download", exportID), nil, &link); err != nil {
+		return nil, err
+	}
+	if link.URL == "" {
+		return nil, fmt.Errorf("export %d: no download url", exportID)
+	}
+
+	// The signed URL carries its own credentials, so no Authorization header
+	// is sent; storage providers reject requests that have both.
+	req, err := http.NewRequestWithContext(ctx, "GET", link.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return nil, &CustomerIOError{
+			status: resp.StatusCode,
+			url:    link.URL,
+			body:   body,
+		}
+	}
+
+	return resp.Body, nil
+}
+
+// ExportRow is a single export row, keyed by column name.
+type ExportRow map[string]string
+
+// ExportDecoder reads rows from an export CSV file as returned by
+// DownloadExport. The first record of the file names the columns.
+type ExportDecoder struct {
+	r       *csv.Reader
+	columns []string
+}
+
+// NewExportDecoder reads the column header from r and returns a decoder for
+// the remaining rows.
+func NewExportDecoder(r io.Reader) (*ExportDecoder, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	return &ExportDecoder{r: cr, columns: append([]string(nil), header...)}, nil
+}
+
+// Columns returns the column names of the export.
+func (d *ExportDecoder) Columns() []string {
+	return d.columns
+}
+
+// Next returns the next row, or io.EOF once every row has been read.
+func (d *ExportDecoder) Next() (ExportRow, error) {
+	record, err := d.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	row := make(ExportRow, len(d.columns))
+	for i, col := range d.columns {
+		if i < len(record) {
+			row[col] = record[i]
+		}
+	}
+	return row, nil
+}
+
+// Decode reads the next row into the struct pointed to by v, or returns
+// io.EOF once every row has been read. Columns are matched to fields by a
+// `csv:"column"` tag, or otherwise by field name. String, bool, integer,
+// float and time.Time (Unix seconds or RFC 3339) fields are supported;
+// empty cells leave the field at its zero value.
+func (d *ExportDecoder) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("customerio: Decode requires a pointer to a struct, got %T", v)
+	}
+
+	row, err := d.Next()
+	if err != nil {
+		return err
+	}
+
+	sv := rv.Elem()
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Tag.Get("csv")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		cell, ok := row[name]
+		if !ok || cell == "" {
+			sv.Field(i).SetZero()
+			continue
+		}
+		if err := setCell(sv.Field(i), cell); err != nil {
+			return fmt.Errorf("column %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func setCell(f reflect.Value, cell string) error {
+	if f.Type() == timeType {
+		if sec, err := strconv.ParseInt(cell, 10, 64); err == nil {
+			f.Set(reflect.ValueOf(time.Unix(sec, 0)))
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339, cell)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(cell)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(cell)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(cell, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(cell, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(cell, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", f.Type())
+	}
+	return nil
+}
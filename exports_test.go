@@ -0,0 +1,198 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+const exportCSV = "id,email,created_at,vip\n1,a@example.com,1500000000,true\n2,b@example.com,,false\n"
+
+func TestExportCustomers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" || req.URL.Path != "/v1/exports/customers" {
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		}
+		b, _ := io.ReadAll(req.Body)
+		if string(b) != `{"filters":{"segment":{"id":4}}}` {
+			t.Errorf("unexpected body %s", b)
+		}
+		_, _ = w.Write([]byte(`{"export":{"id":8,"type":"customers","status":"pending","created_at":1500000000}}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	export, err := api.ExportCustomers(context.Background(), map[string]any{"segment": map[string]any{"id": 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &customerio.Export{ID: 8, Type: "customers", Status: "pending", CreatedAt: time.Unix(1500000000, 0)}
+	if !reflect.DeepEqual(export, want) {
+		t.Errorf("want %#v got %#v", want, export)
+	}
+
+	_, err = api.ExportCustomers(context.Background(), nil)
+	checkParamError(t, err, "filters")
+}
+
+func TestExportDeliveries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/exports/deliveries" {
+			t.Errorf("unexpected path %s", req.URL.Path)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		want := map[string]any{"newsletter_id": float64(3), "metric": "bounced", "start": float64(1500000000)}
+		if !reflect.DeepEqual(body, want) {
+			t.Errorf("want %v got %v", want, body)
+		}
+		_, _ = w.Write([]byte(`{"export":{"id":9,"type":"deliveries"}}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	export, err := api.ExportDeliveries(context.Background(), customerio.DeliveryExportRequest{
+		BroadcastID: 3,
+		Metric:      "bounced",
+		Start:       time.Unix(1500000000, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.ID != 9 {
+		t.Errorf("unexpected export %#v", export)
+	}
+
+	_, err = api.ExportDeliveries(context.Background(), customerio.DeliveryExportRequest{Metric: "sent"})
+	if !errors.Is(err, customerio.ErrNoDeliverySelector) {
+		t.Errorf("expected ErrNoDeliverySelector, got %v", err)
+	}
+}
+
+func TestWaitForExportAndDownload(t *testing.T) {
+	var polls int
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v1/exports/8":
+			polls++
+			status := "pending"
+			if polls > 1 {
+				status = "done"
+			}
+			_, _ = fmt.Fprintf(w, `{"export":{"id":8,"status":%q,"total":2}}`, status)
+		case "/v1/exports/8/download":
+			_, _ = fmt.Fprintf(w, `{"url":%q}`, srv.URL+"/signed/8.csv?sig=abc")
+		case "/signed/8.csv":
+			if req.Header.Get("Authorization") != "" {
+				t.Error("signed download must not send Authorization")
+			}
+			_, _ = w.Write([]byte(exportCSV))
+		default:
+			t.Errorf("unexpected path %s", req.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+	ctx := context.Background()
+
+	export, err := api.WaitForExport(ctx, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !export.Done() || polls != 2 {
+		t.Fatalf("expected done export after 2 polls, got %#v after %d", export, polls)
+	}
+
+	rc, err := api.DownloadExport(ctx, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	dec, err := customerio.NewExportDecoder(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec.Columns(), []string{"id", "email", "created_at", "vip"}) {
+		t.Errorf("unexpected columns %v", dec.Columns())
+	}
+
+	type customer struct {
+		ID        int       `csv:"id"`
+		Email     string    `csv:"email"`
+		CreatedAt time.Time `csv:"created_at"`
+		VIP       bool      `csv:"vip"`
+	}
+	var got []customer
+	for {
+		var c customer
+		if err := dec.Decode(&c); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, c)
+	}
+
+	want := []customer{
+		{ID: 1, Email: "a@example.com", CreatedAt: time.Unix(1500000000, 0), VIP: true},
+		{ID: 2, Email: "b@example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v got %#v", want, got)
+	}
+}
+
+func TestWaitForExportFailed(t *testing.T) {
+	api := appServer(t, "GET", "/v1/exports/8", `{"export":{"id":8,"status":"failed","failed":true}}`)
+
+	export, err := api.WaitForExport(context.Background(), 8)
+	if !errors.Is(err, customerio.ErrExportFailed) {
+		t.Fatalf("expected ErrExportFailed, got %v", err)
+	}
+	if export == nil || export.ID != 8 {
+		t.Errorf("expected export with error, got %#v", export)
+	}
+}
+
+func TestExportDecoderRows(t *testing.T) {
+	dec, err := customerio.NewExportDecoder(strings.NewReader(exportCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row, err := dec.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := customerio.ExportRow{"id": "1", "email": "a@example.com", "created_at": "1500000000", "vip": "true"}
+	if !reflect.DeepEqual(row, want) {
+		t.Errorf("want %v got %v", want, row)
+	}
+
+	var bad struct {
+		ID []string `csv:"id"`
+	}
+	if err := dec.Decode(&bad); err == nil {
+		t.Error("expected error decoding into unsupported field type")
+	}
+	if _, err := dec.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
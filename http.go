@@ -21,11 +21,52 @@ func newDefaultHTTPClient() *http.Client {
 	}
 }
 
+// jsonStreamer is implemented by request bodies that can write their JSON
+// encoding incrementally.
+type jsonStreamer interface {
+	// streamJSON returns a function writing the body and the number of
+	// bytes it writes, or a nil function if the body should be marshaled
+	// normally. The function may be called more than once.
+	streamJSON() (func(io.Writer) error, int64, error)
+}
+
+// pipeBody returns a reader of the bytes written by write, which runs in its
+// own goroutine. Closing the reader unblocks write if the client stopped
+// reading early.
+func pipeBody(write func(io.Writer) error) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() { _ = pw.CloseWithError(write(pw)) }()
+	return pr
+}
+
 // doHTTP is the shared HTTP execution path for both CustomerIO (Track) and
 // APIClient (App API). Auth header injection is caller-supplied via setAuth.
 func doHTTP(ctx context.Context, client HTTPClient, method, url, userAgent string, body any, preflight func(*http.Request)) ([]byte, int, error) {
+	var write func(io.Writer) error
+	var size int64
+	if s, ok := body.(jsonStreamer); ok {
+		var err error
+		if write, size, err = s.streamJSON(); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	var req *http.Request
-	if body != nil {
+	if write != nil {
+		// Stream large bodies rather than marshaling them in memory. The
+		// length is known up front, and GetBody lets the transport replay
+		// the body on a retry or redirect.
+		pr := pipeBody(write)
+		defer func() { _ = pr.Close() }()
+		var err error
+		req, err = http.NewRequestWithContext(ctx, method, url, pr)
+		if err != nil {
+			return nil, 0, err
+		}
+		req.ContentLength = size
+		req.GetBody = func() (io.ReadCloser, error) { return pipeBody(write), nil }
+		req.Header.Set("Content-Type", "application/json")
+	} else if body != nil {
 		b, err := json.Marshal(body)
 		if err != nil {
 			return nil, 0, err
@@ -0,0 +1,411 @@
+// Package importer bulk imports customers and events into Customer.io from
+// CSV or JSONL files, such as warehouse dumps.
+//
+// Each row is mapped onto an identify or track call by a Mapping. Events keep
+// their historic timestamps and are sent with stable event IDs, and progress
+// is recorded in a checkpoint file, so an interrupted import can be rerun
+// without duplicating events.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+// Client is the subset of *customerio.CustomerIO used by the importer.
+type Client interface {
+	IdentifyCtx(ctx context.Context, customerID string, attributes map[string]any) error
+	TrackCtx(ctx context.Context, customerID string, eventName string, data map[string]any, opts ...customerio.TrackOption) error
+}
+
+// Format is the encoding of the input file.
+type Format string
+
+const (
+	// FormatCSV reads comma separated values with a header row naming the columns.
+	FormatCSV Format = "csv"
+	// FormatJSONL reads one JSON object per line.
+	FormatJSONL Format = "jsonl"
+)
+
+// ErrCheckpointMismatch is returned when the checkpoint file was written by
+// an import of a different input.
+var ErrCheckpointMismatch = errors.New("importer: checkpoint was written for a different input")
+
+// fingerprintSize is how much of the start of the input identifies it in a
+// checkpoint.
+const fingerprintSize = 64 << 10
+
+// DefaultConcurrency is the number of concurrent requests used when
+// Options.Concurrency is zero.
+const DefaultConcurrency = 4
+
+// Options configures an import.
+type Options struct {
+	Format  Format
+	Mapping Mapping
+	// Concurrency bounds the number of requests in flight. Rows with the same
+	// customer ID are always sent one at a time, in input order.
+	Concurrency int
+	// CheckpointPath, if set, names a file recording the last row up to which
+	// every row was imported. Rows up to that point are skipped when the
+	// import is rerun with the same input; rerunning with a different input
+	// fails with ErrCheckpointMismatch.
+	CheckpointPath string
+	// CheckpointEvery is how many completed rows pass between checkpoint
+	// writes; zero means 100. The checkpoint is always written on return.
+	CheckpointEvery int
+}
+
+// RowError records a row that could not be imported.
+type RowError struct {
+	// Row is the one-based row number, not counting a CSV header.
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string { return fmt.Sprintf("row %d: %v", e.Row, e.Err) }
+
+func (e RowError) Unwrap() error { return e.Err }
+
+// Result summarizes an import.
+type Result struct {
+	// Imported is the number of rows sent successfully.
+	Imported int
+	// Skipped is the number of rows skipped because of the checkpoint.
+	Skipped int
+	// Failed lists the rows that could not be imported, in row order.
+	Failed []RowError
+}
+
+// Import reads rows from r and sends them to client. Rows that fail are
+// reported in the Result rather than stopping the import; the returned error
+// is reserved for problems with the input, the checkpoint, or ctx.
+func Import(ctx context.Context, client Client, r io.Reader, opts Options) (*Result, error) {
+	if err := opts.Mapping.validate(); err != nil {
+		return nil, err
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.CheckpointEvery <= 0 {
+		opts.CheckpointEvery = 100
+	}
+
+	br := bufio.NewReaderSize(r, fingerprintSize)
+	head, err := br.Peek(fingerprintSize)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	sum := sha256.Sum256(head)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	rows, err := newReader(opts.Format, br)
+	if err != nil {
+		return nil, err
+	}
+
+	cp, err := loadCheckpoint(opts.CheckpointPath, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res     Result
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		rerr    error
+		saveErr error
+	)
+
+	// Rows are sharded by customer ID across the workers, so the rows of one
+	// customer are sent one at a time in input order.
+	type job struct {
+		n   int
+		row map[string]any
+	}
+	shards := make([]chan job, opts.Concurrency)
+	for i := range shards {
+		shards[i] = make(chan job, 1)
+		wg.Add(1)
+		go func(jobs <-chan job) {
+			defer wg.Done()
+			for j := range jobs {
+				err := send(ctx, client, opts.Mapping, j.n, j.row)
+
+				mu.Lock()
+				if err != nil {
+					res.Failed = append(res.Failed, RowError{Row: j.n, Err: err})
+					cp.fail(j.n)
+				} else {
+					res.Imported++
+					cp.finish(j.n)
+					if res.Imported%opts.CheckpointEvery == 0 {
+						if err := cp.save(); err != nil && saveErr == nil {
+							saveErr = err
+						}
+					}
+				}
+				mu.Unlock()
+			}
+		}(shards[i])
+	}
+
+	for n := 1; ; n++ {
+		row, err := rows.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rerr = fmt.Errorf("row %d: %w", n, err)
+			break
+		}
+		if n <= cp.done {
+			res.Skipped++
+			continue
+		}
+		if ctx.Err() != nil {
+			rerr = ctx.Err()
+			break
+		}
+
+		mu.Lock()
+		cp.start(n)
+		mu.Unlock()
+		h := fnv.New32a()
+		_, _ = io.WriteString(h, stringValue(row[opts.Mapping.CustomerID]))
+		shards[h.Sum32()%uint32(len(shards))] <- job{n, row}
+	}
+	for _, jobs := range shards {
+		close(jobs)
+	}
+	wg.Wait()
+
+	sort.Slice(res.Failed, func(i, j int) bool { return res.Failed[i].Row < res.Failed[j].Row })
+	if rerr == nil {
+		rerr = saveErr
+	}
+	if err := cp.save(); err != nil && rerr == nil {
+		rerr = err
+	}
+	return &res, rerr
+}
+
+// send imports row, the nth row of the input.
+func send(ctx context.Context, client Client, m Mapping, n int, row map[string]any) error {
+	customerID := stringValue(row[m.CustomerID])
+	if customerID == "" {
+		return customerio.ParamError{Param: m.CustomerID}
+	}
+
+	if m.Type == TypeIdentify {
+		return client.IdentifyCtx(ctx, customerID, m.fields(row))
+	}
+
+	name := m.FixedEventName
+	if m.EventName != "" {
+		if v := stringValue(row[m.EventName]); v != "" {
+			name = v
+		}
+	}
+
+	var opts []customerio.TrackOption
+	var ts time.Time
+	if m.Timestamp != "" && stringValue(row[m.Timestamp]) != "" {
+		t, err := parseTimestamp(row[m.Timestamp])
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.Timestamp, err)
+		}
+		if t.Before(time.Unix(0, 0)) {
+			return fmt.Errorf("%s: %s is before 1970", m.Timestamp, t.UTC().Format(time.RFC3339))
+		}
+		ts = t
+		opts = append(opts, customerio.WithEventTimestamp(ts))
+	}
+
+	if id := stringValue(row[m.EventID]); m.EventID != "" && id != "" {
+		opts = append(opts, customerio.WithEventID(id))
+	} else {
+		// json.Marshal sorts map keys, so a row produces the same key on
+		// every run. The row number keeps identical rows distinct events.
+		key, err := json.Marshal(struct {
+			Row  int            `json:"row"`
+			Data map[string]any `json:"data"`
+		}{n, row})
+		if err != nil {
+			return err
+		}
+		opts = append(opts, customerio.WithEventID(stableULID(ts, key)))
+	}
+
+	return client.TrackCtx(ctx, customerID, name, m.fields(row), opts...)
+}
+
+type rowReader interface {
+	next() (map[string]any, error)
+}
+
+func newReader(f Format, r io.Reader) (rowReader, error) {
+	switch f {
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		header, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("importer: reading csv header: %w", err)
+		}
+		return &csvReader{r: cr, header: header}, nil
+	case FormatJSONL:
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 16<<20)
+		return &jsonlReader{sc: sc}, nil
+	default:
+		return nil, fmt.Errorf("importer: unknown format %q", f)
+	}
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+func (c *csvReader) next() (map[string]any, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	row := make(map[string]any, len(c.header))
+	for i, col := range c.header {
+		if i < len(record) {
+			row[col] = record[i]
+		}
+	}
+	return row, nil
+}
+
+type jsonlReader struct {
+	sc *bufio.Scanner
+}
+
+func (j *jsonlReader) next() (map[string]any, error) {
+	for j.sc.Scan() {
+		line := bytes.TrimSpace(j.sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		var row map[string]any
+		if err := dec.Decode(&row); err != nil {
+			return nil, err
+		}
+		return row, nil
+	}
+	if err := j.sc.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// checkpoint tracks the highest row number up to which every row has been
+// imported. Rows complete out of order under concurrency, so in-flight and
+// failed rows hold the checkpoint back; rows after them are resent by a rerun,
+// which is safe because identify calls and events with stable IDs are
+// idempotent.
+//
+// The checkpoint file holds the row number and a fingerprint of the start of
+// the input, so a checkpoint is never applied to a different file.
+type checkpoint struct {
+	path        string
+	fingerprint string
+	// done is the row number loaded from the checkpoint file.
+	done int
+	// next is the row number following the last row started.
+	next        int
+	pending     map[int]bool
+	firstFailed int
+}
+
+func loadCheckpoint(path, fingerprint string) (*checkpoint, error) {
+	cp := &checkpoint{path: path, fingerprint: fingerprint, pending: map[int]bool{}}
+	if path != "" {
+		b, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, err
+		default:
+			fields := strings.Fields(string(b))
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("importer: invalid checkpoint %s", path)
+			}
+			cp.done, err = strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("importer: invalid checkpoint %s: %w", path, err)
+			}
+			if fields[1] != fingerprint {
+				return nil, fmt.Errorf("%w: %s", ErrCheckpointMismatch, path)
+			}
+		}
+	}
+	cp.next = cp.done + 1
+	return cp, nil
+}
+
+func (c *checkpoint) start(n int) {
+	c.pending[n] = true
+	c.next = n + 1
+}
+
+func (c *checkpoint) finish(n int) {
+	delete(c.pending, n)
+}
+
+func (c *checkpoint) fail(n int) {
+	delete(c.pending, n)
+	if c.firstFailed == 0 || n < c.firstFailed {
+		c.firstFailed = n
+	}
+}
+
+// watermark returns the highest row number up to which every row is imported.
+func (c *checkpoint) watermark() int {
+	low := c.next
+	for n := range c.pending {
+		if n < low {
+			low = n
+		}
+	}
+	if c.firstFailed > 0 && c.firstFailed < low {
+		low = c.firstFailed
+	}
+	return low - 1
+}
+
+func (c *checkpoint) save() error {
+	if c.path == "" {
+		return nil
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.Itoa(c.watermark())+" "+c.fingerprint+"\n"), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
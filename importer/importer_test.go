@@ -0,0 +1,299 @@
+package importer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+	"github.com/customerio/go-customerio/v3/importer"
+)
+
+type call struct {
+	customerID string
+	name       string
+	data       map[string]any
+	payload    map[string]any
+}
+
+// recorder is an importer.Client that records calls and fails for the
+// customer IDs in fail.
+type recorder struct {
+	mu    sync.Mutex
+	calls []call
+	fail  map[string]bool
+}
+
+func (r *recorder) IdentifyCtx(_ context.Context, customerID string, attributes map[string]any) error {
+	return r.record(call{customerID: customerID, data: attributes})
+}
+
+func (r *recorder) TrackCtx(_ context.Context, customerID string, eventName string, data map[string]any, opts ...customerio.TrackOption) error {
+	payload := map[string]any{}
+	for _, opt := range opts {
+		opt(payload)
+	}
+	return r.record(call{customerID: customerID, name: eventName, data: data, payload: payload})
+}
+
+func (r *recorder) record(c call) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fail[c.customerID] {
+		return errors.New("boom")
+	}
+	r.calls = append(r.calls, c)
+	return nil
+}
+
+func (r *recorder) sorted() []call {
+	sort.Slice(r.calls, func(i, j int) bool { return r.calls[i].customerID < r.calls[j].customerID })
+	return r.calls
+}
+
+func TestImportIdentifyCSV(t *testing.T) {
+	in := "user_id,email,plan\n1,a@example.com,basic\n2,b@example.com,pro\n"
+	rec := &recorder{}
+
+	res, err := importer.Import(context.Background(), rec, strings.NewReader(in), importer.Options{
+		Format: importer.FormatCSV,
+		Mapping: importer.Mapping{
+			Type:       importer.TypeIdentify,
+			CustomerID: "user_id",
+			Fields:     map[string]string{"email": "email"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Imported != 2 {
+		t.Errorf("expected 2 imported, got %#v", res)
+	}
+
+	want := []call{
+		{customerID: "1", data: map[string]any{"email": "a@example.com"}},
+		{customerID: "2", data: map[string]any{"email": "b@example.com"}},
+	}
+	if got := rec.sorted(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v got %#v", want, got)
+	}
+}
+
+func TestImportEventsJSONL(t *testing.T) {
+	in := `{"user_id":"1","event":"purchase","ts":1500000000,"total":12.5}
+{"user_id":"2","ts":"2017-07-14T02:40:00Z","total":3,"id":"01BX5ZZKBKACTAV9WEVGEMMVRZ"}
+`
+	mapping := importer.Mapping{
+		Type:           importer.TypeEvent,
+		CustomerID:     "user_id",
+		EventName:      "event",
+		FixedEventName: "order",
+		Timestamp:      "ts",
+		EventID:        "id",
+	}
+
+	run := func() []call {
+		rec := &recorder{}
+		if _, err := importer.Import(context.Background(), rec, strings.NewReader(in), importer.Options{
+			Format:  importer.FormatJSONL,
+			Mapping: mapping,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return rec.sorted()
+	}
+
+	calls := run()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(calls))
+	}
+
+	first := calls[0]
+	if first.name != "purchase" || first.payload["timestamp"] != int64(1500000000) {
+		t.Errorf("unexpected first call %#v", first)
+	}
+	if id, _ := first.payload["id"].(string); len(id) != 26 {
+		t.Errorf("expected derived ULID event id, got %q", id)
+	}
+	if _, ok := first.data["user_id"]; ok {
+		t.Error("mapped columns must not be repeated in event data")
+	}
+	if first.data["total"] == nil {
+		t.Error("expected unmapped columns in event data")
+	}
+
+	second := calls[1]
+	if second.name != "order" || second.payload["id"] != "01BX5ZZKBKACTAV9WEVGEMMVRZ" {
+		t.Errorf("unexpected second call %#v", second)
+	}
+	if second.payload["timestamp"] != time.Date(2017, 7, 14, 2, 40, 0, 0, time.UTC).Unix() {
+		t.Errorf("unexpected timestamp %v", second.payload["timestamp"])
+	}
+
+	if again := run(); again[0].payload["id"] != first.payload["id"] {
+		t.Errorf("event id not stable across runs: %v != %v", again[0].payload["id"], first.payload["id"])
+	}
+}
+
+func TestImportResumesFromCheckpoint(t *testing.T) {
+	in := "user_id\n1\n2\n3\n4\n"
+	cpPath := filepath.Join(t.TempDir(), "import.ckpt")
+	opts := importer.Options{
+		Format:         importer.FormatCSV,
+		Mapping:        importer.Mapping{Type: importer.TypeIdentify, CustomerID: "user_id"},
+		Concurrency:    1,
+		CheckpointPath: cpPath,
+	}
+
+	rec := &recorder{fail: map[string]bool{"3": true}}
+	res, err := importer.Import(context.Background(), rec, strings.NewReader(in), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Imported != 3 || len(res.Failed) != 1 || res.Failed[0].Row != 3 {
+		t.Fatalf("unexpected result %#v", res)
+	}
+
+	b, err := os.ReadFile(cpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields := strings.Fields(string(b)); len(fields) != 2 || fields[0] != "2" {
+		t.Errorf("expected checkpoint held at row 2, got %q", b)
+	}
+
+	rec = &recorder{}
+	res, err = importer.Import(context.Background(), rec, strings.NewReader(in), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Skipped != 2 || res.Imported != 2 {
+		t.Errorf("unexpected resumed result %#v", res)
+	}
+	if got := rec.sorted(); len(got) != 2 || got[0].customerID != "3" || got[1].customerID != "4" {
+		t.Errorf("unexpected resumed calls %#v", got)
+	}
+}
+
+func TestImportRejectsCheckpointOfOtherInput(t *testing.T) {
+	cpPath := filepath.Join(t.TempDir(), "import.ckpt")
+	opts := importer.Options{
+		Format:         importer.FormatCSV,
+		Mapping:        importer.Mapping{Type: importer.TypeIdentify, CustomerID: "user_id"},
+		CheckpointPath: cpPath,
+	}
+	if _, err := importer.Import(context.Background(), &recorder{}, strings.NewReader("user_id\n1\n2\n"), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &recorder{}
+	_, err := importer.Import(context.Background(), rec, strings.NewReader("user_id\n7\n8\n"), opts)
+	if !errors.Is(err, importer.ErrCheckpointMismatch) {
+		t.Errorf("expected ErrCheckpointMismatch, got %v", err)
+	}
+	if len(rec.calls) != 0 {
+		t.Errorf("unexpected calls %#v", rec.calls)
+	}
+}
+
+func TestImportKeepsCustomerRowOrder(t *testing.T) {
+	var in strings.Builder
+	in.WriteString("user_id,step\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&in, "%d,%d\n", i%3, i)
+	}
+	rec := &recorder{}
+	_, err := importer.Import(context.Background(), rec, strings.NewReader(in.String()), importer.Options{
+		Format:      importer.FormatCSV,
+		Mapping:     importer.Mapping{Type: importer.TypeIdentify, CustomerID: "user_id"},
+		Concurrency: 8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := map[string]int{}
+	for _, c := range rec.calls {
+		step, _ := strconv.Atoi(c.data["step"].(string))
+		if prev, ok := last[c.customerID]; ok && step < prev {
+			t.Fatalf("customer %s: step %d sent after %d", c.customerID, step, prev)
+		}
+		last[c.customerID] = step
+	}
+	if len(rec.calls) != 200 {
+		t.Errorf("expected 200 calls, got %d", len(rec.calls))
+	}
+}
+
+func TestLoadMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mapping.json")
+	if err := os.WriteFile(path, []byte(`{"type":"event","customer_id":"id"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := importer.LoadMapping(path); err == nil {
+		t.Error("expected error for event mapping without a name")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"type":"event","customer_id":"id","fixed_event_name":"signup"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := importer.LoadMapping(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.FixedEventName != "signup" {
+		t.Errorf("unexpected mapping %#v", m)
+	}
+}
+
+func TestImportKeepsIdenticalEventsDistinct(t *testing.T) {
+	in := `{"user_id":"1","total":3}
+{"user_id":"1","total":3}
+{"user_id":"2","ts":-86400}
+`
+	mapping := importer.Mapping{
+		Type:           importer.TypeEvent,
+		CustomerID:     "user_id",
+		FixedEventName: "order",
+		Timestamp:      "ts",
+	}
+
+	run := func() ([]call, *importer.Result) {
+		rec := &recorder{}
+		res, err := importer.Import(context.Background(), rec, strings.NewReader(in), importer.Options{
+			Format:  importer.FormatJSONL,
+			Mapping: mapping,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rec.calls, res
+	}
+
+	calls, res := run()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(calls))
+	}
+	ids := map[any]bool{calls[0].payload["id"]: true, calls[1].payload["id"]: true}
+	if len(ids) != 2 {
+		t.Errorf("identical rows were given the same event id %v", calls[0].payload["id"])
+	}
+	again, _ := run()
+	for _, c := range again {
+		if !ids[c.payload["id"]] {
+			t.Errorf("event id %v not stable across runs", c.payload["id"])
+		}
+	}
+
+	if len(res.Failed) != 1 || res.Failed[0].Row != 3 || !strings.Contains(res.Failed[0].Error(), "before 1970") {
+		t.Errorf("expected row 3 to fail for a pre-1970 timestamp, got %v", res.Failed)
+	}
+}
@@ -0,0 +1,117 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Type selects the Track operation a row is imported as.
+type Type string
+
+const (
+	// TypeIdentify imports each row as customer attributes via IdentifyCtx.
+	TypeIdentify Type = "identify"
+	// TypeEvent imports each row as an event via TrackCtx.
+	TypeEvent Type = "event"
+)
+
+// Mapping describes how the columns of a row map onto a Track operation. For
+// JSONL input, columns are the top-level keys of each object.
+type Mapping struct {
+	Type Type `json:"type"`
+	// CustomerID is the column holding the customer ID. Required.
+	CustomerID string `json:"customer_id"`
+	// EventName is the column holding the event name. If it is empty, or a
+	// row has no value for it, FixedEventName is used instead.
+	EventName      string `json:"event_name,omitempty"`
+	FixedEventName string `json:"fixed_event_name,omitempty"`
+	// Timestamp is the column holding the event time, as Unix seconds or RFC
+	// 3339. It is sent with customerio.WithEventTimestamp.
+	Timestamp string `json:"timestamp,omitempty"`
+	// EventID is the column holding a stable event ID, sent with
+	// customerio.WithEventID. If it is empty, an ID is derived from the row's
+	// contents so that rerunning an import doesn't duplicate events.
+	EventID string `json:"event_id,omitempty"`
+	// Fields maps attribute or event data names to the columns holding their
+	// values. If it is empty, every column not used above is included under
+	// its own name.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// LoadMapping reads a JSON mapping spec from path.
+func LoadMapping(path string) (Mapping, error) {
+	var m Mapping
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return m, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, m.validate()
+}
+
+func (m Mapping) validate() error {
+	switch m.Type {
+	case TypeIdentify:
+	case TypeEvent:
+		if m.EventName == "" && m.FixedEventName == "" {
+			return errors.New("importer: event mapping requires event_name or fixed_event_name")
+		}
+	default:
+		return fmt.Errorf("importer: unknown mapping type %q", m.Type)
+	}
+	if m.CustomerID == "" {
+		return errors.New("importer: mapping requires customer_id")
+	}
+	return nil
+}
+
+// fields returns the attributes or event data for row.
+func (m Mapping) fields(row map[string]any) map[string]any {
+	out := map[string]any{}
+	if len(m.Fields) > 0 {
+		for name, col := range m.Fields {
+			if v, ok := row[col]; ok {
+				out[name] = v
+			}
+		}
+		return out
+	}
+
+	for col, v := range row {
+		switch col {
+		case m.CustomerID, m.EventName, m.Timestamp, m.EventID:
+			if col != "" {
+				continue
+			}
+		}
+		out[col] = v
+	}
+	return out
+}
+
+func stringValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func parseTimestamp(v any) (time.Time, error) {
+	s := stringValue(v)
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,50 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"time"
+)
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// maxULIDTime is the largest millisecond timestamp a ULID's 48 bit time
+// component holds.
+const maxULIDTime = 1<<48 - 1
+
+// stableULID derives a ULID from t and key: the timestamp component comes
+// from t, clamped to the range a ULID can hold with times before 1970 and
+// the zero time using the Unix epoch, and the random component from a hash of
+// key, so the same row always yields the same event ID. Customer.io requires
+// event IDs to be ULIDs.
+func stableULID(t time.Time, key []byte) string {
+	var id [16]byte
+
+	var ms uint64
+	if !t.IsZero() && t.UnixMilli() > 0 {
+		ms = min(uint64(t.UnixMilli()), maxULIDTime)
+	}
+	for i := 5; i >= 0; i-- {
+		id[i] = byte(ms)
+		ms >>= 8
+	}
+	sum := sha256.Sum256(key)
+	copy(id[6:], sum[:10])
+
+	// 128 bits encode to 26 base32 characters, the first holding 3 bits.
+	var out [26]byte
+	var acc uint64
+	var bits uint
+	pos := 25
+	for i := 15; i >= 0; i-- {
+		acc |= uint64(id[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			out[pos] = crockford[acc&31]
+			acc >>= 5
+			bits -= 5
+			pos--
+		}
+	}
+	out[0] = crockford[acc&31]
+	return string(out[:])
+}
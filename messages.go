@@ -0,0 +1,208 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Message describes a single message delivery, such as one returned as
+// TransactionalResponse.DeliveryID.
+type Message struct {
+	// ID is the delivery ID of the message.
+	ID            string `json:"id"`
+	DeduplicateID string `json:"deduplicate_id"`
+	// Type is the message channel, e.g. "email", "push" or "sms".
+	Type string `json:"type"`
+	// Recipient is the address the message was sent to.
+	Recipient           string            `json:"recipient"`
+	Subject             string            `json:"subject"`
+	CustomerID          string            `json:"customer_id"`
+	CustomerIdentifiers map[string]string `json:"customer_identifiers"`
+	// TransactionalMessageID is set for transactional messages.
+	TransactionalMessageID int `json:"transactional_message_id"`
+	CampaignID             int `json:"campaign_id"`
+	BroadcastID            int `json:"broadcast_id"`
+	ActionID               int `json:"action_id"`
+	ContentID              int `json:"content_id"`
+	// FailureMessage explains why the message failed or bounced, if it did.
+	FailureMessage string         `json:"failure_message"`
+	Metrics        MessageMetrics `json:"metrics"`
+	CreatedAt      time.Time      `json:"created"`
+}
+
+func (m *Message) UnmarshalJSON(b []byte) error {
+	type message Message
+	var r struct {
+		*message
+		CreatedAt int64 `json:"created"`
+	}
+	r.message = (*message)(m)
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	m.CreatedAt = unixTime(r.CreatedAt)
+	return nil
+}
+
+// MessageMetrics records when each delivery state was reached. States the
+// message has not reached are the zero time.
+type MessageMetrics struct {
+	Sent         time.Time
+	Delivered    time.Time
+	Opened       time.Time
+	Clicked      time.Time
+	Converted    time.Time
+	Bounced      time.Time
+	Spammed      time.Time
+	Unsubscribed time.Time
+	Failed       time.Time
+}
+
+func (m *MessageMetrics) UnmarshalJSON(b []byte) error {
+	var r struct {
+		Sent         int64 `json:"sent"`
+		Delivered    int64 `json:"delivered"`
+		Opened       int64 `json:"opened"`
+		Clicked      int64 `json:"clicked"`
+		Converted    int64 `json:"converted"`
+		Bounced      int64 `json:"bounced"`
+		Spammed      int64 `json:"spammed"`
+		Unsubscribed int64 `json:"unsubscribed"`
+		Failed       int64 `json:"failed"`
+	}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	*m = MessageMetrics{
+		Sent:         unixTime(r.Sent),
+		Delivered:    unixTime(r.Delivered),
+		Opened:       unixTime(r.Opened),
+		Clicked:      unixTime(r.Clicked),
+		Converted:    unixTime(r.Converted),
+		Bounced:      unixTime(r.Bounced),
+		Spammed:      unixTime(r.Spammed),
+		Unsubscribed: unixTime(r.Unsubscribed),
+		Failed:       unixTime(r.Failed),
+	}
+	return nil
+}
+
+// MessageList is a page of message deliveries.
+type MessageList struct {
+	Messages []Message `json:"messages"`
+	// Next is the cursor for the following page, empty on the last page.
+	Next string `json:"next"`
+}
+
+// MessageFilter narrows the deliveries returned by ListMessages. Zero values
+// are not sent.
+type MessageFilter struct {
+	// Type restricts results to one channel, e.g. "email", "push" or "sms".
+	Type string
+	// Metric restricts results to messages that reached a state, e.g.
+	// "delivered", "bounced" or "failed".
+	Metric                 string
+	CampaignID             int
+	BroadcastID            int
+	ActionID               int
+	TransactionalMessageID int
+	Start                  time.Time
+	End                    time.Time
+	PageOptions
+}
+
+func (f MessageFilter) values() url.Values {
+	v := f.PageOptions.values()
+	if f.Type != "" {
+		v.Set("type", f.Type)
+	}
+	if f.Metric != "" {
+		v.Set("metric", f.Metric)
+	}
+	if f.CampaignID > 0 {
+		v.Set("campaign_id", strconv.Itoa(f.CampaignID))
+	}
+	if f.BroadcastID > 0 {
+		v.Set("newsletter_id", strconv.Itoa(f.BroadcastID))
+	}
+	if f.ActionID > 0 {
+		v.Set("action_id", strconv.Itoa(f.ActionID))
+	}
+	if f.TransactionalMessageID > 0 {
+		v.Set("transactional_message_id", strconv.Itoa(f.TransactionalMessageID))
+	}
+	if !f.Start.IsZero() {
+		v.Set("start_ts", strconv.FormatInt(f.Start.Unix(), 10))
+	}
+	if !f.End.IsZero() {
+		v.Set("end_ts", strconv.FormatInt(f.End.Unix(), 10))
+	}
+	return v
+}
+
+// ArchivedMessage is the rendered content of a delivered message, as archived
+// by Customer.io. Fields that don't apply to the message's channel are empty.
+type ArchivedMessage struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	ReplyTo   string `json:"reply_to"`
+	BCC       string `json:"bcc"`
+	Subject   string `json:"subject"`
+	Preheader string `json:"preheader"`
+	Body      string `json:"body"`
+	// PlaintextBody is the plain text alternative of an email body.
+	PlaintextBody string `json:"body_plain"`
+	AMPBody       string `json:"body_amp"`
+	// Headers holds any custom email headers that were sent.
+	Headers map[string]string `json:"headers"`
+}
+
+// GetMessage returns the delivery state and metrics of a single message, such
+// as the TransactionalResponse.DeliveryID returned from a send.
+// See https://docs.customer.io/api/app/#operation/getMessage
+func (c *APIClient) GetMessage(ctx context.Context, deliveryID string) (*Message, error) {
+	if deliveryID == "" {
+		return nil, ParamError{Param: "deliveryID"}
+	}
+
+	var resp struct {
+		Message Message `json:"message"`
+	}
+	if err := c.requestJSON(ctx, "GET", formatPath("/v1/messages/%s", deliveryID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Message, nil
+}
+
+// ListMessages returns a page of message deliveries matching filter.
+// See https://docs.customer.io/api/app/#operation/listMessages
+func (c *APIClient) ListMessages(ctx context.Context, filter MessageFilter) (*MessageList, error) {
+	var list MessageList
+	if err := c.requestJSON(ctx, "GET", withQuery("/v1/messages", filter.values()), nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetArchivedMessage returns the rendered content of a message as it was
+// delivered. Content is only available while it is within the workspace's
+// message retention period and was not sent with DisableMessageRetention.
+// See https://docs.customer.io/api/app/#operation/getArchivedMessage
+func (c *APIClient) GetArchivedMessage(ctx context.Context, deliveryID string) (*ArchivedMessage, error) {
+	if deliveryID == "" {
+		return nil, ParamError{Param: "deliveryID"}
+	}
+
+	var resp struct {
+		Message ArchivedMessage `json:"message"`
+	}
+	if err := c.requestJSON(ctx, "GET", formatPath("/v1/messages/%s/archived_message", deliveryID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Message, nil
+}
@@ -0,0 +1,95 @@
+package customerio_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestGetMessage(t *testing.T) {
+	api := appServer(t, "GET", "/v1/messages/"+testDeliveryID, `{"message":{
+		"id":"`+testDeliveryID+`",
+		"type":"email",
+		"recipient":"customer@example.com",
+		"customer_identifiers":{"id":"customer_1","email":"customer@example.com"},
+		"transactional_message_id":4,
+		"failure_message":"mailbox full",
+		"created":1500000000,
+		"metrics":{"sent":1500000001,"delivered":1500000002,"opened":1500000003,"clicked":1500000004,"bounced":1500000005,"failed":1500000006}
+	}}`)
+
+	msg, err := api.GetMessage(context.Background(), testDeliveryID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &customerio.Message{
+		ID:                     testDeliveryID,
+		Type:                   "email",
+		Recipient:              "customer@example.com",
+		CustomerIdentifiers:    map[string]string{"id": "customer_1", "email": "customer@example.com"},
+		TransactionalMessageID: 4,
+		FailureMessage:         "mailbox full",
+		CreatedAt:              time.Unix(1500000000, 0),
+		Metrics: customerio.MessageMetrics{
+			Sent:      time.Unix(1500000001, 0),
+			Delivered: time.Unix(1500000002, 0),
+			Opened:    time.Unix(1500000003, 0),
+			Clicked:   time.Unix(1500000004, 0),
+			Bounced:   time.Unix(1500000005, 0),
+			Failed:    time.Unix(1500000006, 0),
+		},
+	}
+	if !reflect.DeepEqual(msg, want) {
+		t.Errorf("want %#v got %#v", want, msg)
+	}
+	if !msg.Metrics.Converted.IsZero() {
+		t.Error("expected unset metric to be the zero time")
+	}
+
+	_, err = api.GetMessage(context.Background(), "")
+	checkParamError(t, err, "deliveryID")
+}
+
+func TestListMessages(t *testing.T) {
+	api := appServer(t, "GET", "/v1/messages?end_ts=1500086400&limit=5&metric=bounced&start_ts=1500000000&transactional_message_id=4&type=email", `{"messages":[{"id":"a"},{"id":"b"}],"next":"c"}`)
+
+	list, err := api.ListMessages(context.Background(), customerio.MessageFilter{
+		Type:                   "email",
+		Metric:                 "bounced",
+		TransactionalMessageID: 4,
+		Start:                  time.Unix(1500000000, 0),
+		End:                    time.Unix(1500086400, 0),
+		PageOptions:            customerio.PageOptions{Limit: 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Messages) != 2 || list.Messages[1].ID != "b" || list.Next != "c" {
+		t.Errorf("unexpected list %#v", list)
+	}
+}
+
+func TestGetArchivedMessage(t *testing.T) {
+	api := appServer(t, "GET", "/v1/messages/"+testDeliveryID+"/archived_message", `{"message":{"id":"`+testDeliveryID+`","type":"email","to":"customer@example.com","subject":"Hi","body":"<p>Hi</p>","body_plain":"Hi"}}`)
+
+	msg, err := api.GetArchivedMessage(context.Background(), testDeliveryID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &customerio.ArchivedMessage{
+		ID:            testDeliveryID,
+		Type:          "email",
+		To:            "customer@example.com",
+		Subject:       "Hi",
+		Body:          "<p>Hi</p>",
+		PlaintextBody: "Hi",
+	}
+	if !reflect.DeepEqual(msg, want) {
+		t.Errorf("want %#v got %#v", want, msg)
+	}
+}
@@ -1,22 +1,45 @@
 package customerio
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"time"
 )
 
 // Option configures Customer.io API and Track clients.
+//
+// Options built from invalid input carry an error instead of panicking when
+// they are created. NewTrackClientE and NewAPIClientE return that error;
+// NewTrackClient and NewAPIClient panic with it.
 type Option interface {
 	applyAPI(*APIClient)
 	applyTrack(*CustomerIO)
+	validate() error
 }
 
 type option struct {
 	api   func(*APIClient)
 	track func(*CustomerIO)
+
+	// err is set when the option was built from invalid input.
+	err error
+	// insecure permits a plain http base URL in the validating constructors.
+	insecure bool
+	// credentials is set by WithCredentialsProvider, making constructor
+	// credentials optional.
+	credentials bool
+	// region is the region selected by WithRegion.
+	region Region
+	// url is set by WithURL.
+	url bool
+	// auto is set by WithAutoRegion; the region is detected when a client is
+	// built.
+	auto *autoRegion
 }
 
+func (o option) validate() error { return o.err }
+
 func (o option) applyAPI(a *APIClient) {
 	if o.api != nil {
 		o.api(a)
@@ -57,7 +80,7 @@ func WithRegion(r Region) Option {
 	switch r {
 	case RegionUS, RegionEU:
 	default:
-		panic(fmt.Sprintf("customerio: unknown region %q", r))
+		return option{err: fmt.Errorf("customerio: unknown region %q", r)}
 	}
 	return option{
 		api: func(a *APIClient) {
@@ -66,12 +89,13 @@ func WithRegion(r Region) Option {
 		track: func(c *CustomerIO) {
 			c.URL = r.TrackURL()
 		},
+		region: r,
 	}
 }
 
 func WithHTTPClient(client HTTPClient) Option {
 	if client == nil {
-		panic("customerio: WithHTTPClient called with nil HTTPClient")
+		return option{err: errors.New("customerio: WithHTTPClient called with nil HTTPClient")}
 	}
 	return option{
 		api: func(a *APIClient) {
@@ -87,7 +111,7 @@ func WithHTTPClient(client HTTPClient) Option {
 // WithRegion instead; this is intended for tests or on-premise deployments.
 func WithURL(url string) Option {
 	if url == "" {
-		panic("customerio: WithURL called with empty string")
+		return option{err: errors.New("customerio: WithURL called with empty string")}
 	}
 	return option{
 		api: func(a *APIClient) {
@@ -96,12 +120,13 @@ func WithURL(url string) Option {
 		track: func(c *CustomerIO) {
 			c.URL = url
 		},
+		url: true,
 	}
 }
 
 func WithUserAgent(ua string) Option {
 	if ua == "" {
-		panic("customerio: WithUserAgent called with empty string")
+		return option{err: errors.New("customerio: WithUserAgent called with empty string")}
 	}
 	return option{
 		api: func(a *APIClient) {
@@ -113,6 +138,66 @@ func WithUserAgent(ua string) Option {
 	}
 }
 
+// WithInsecureURL allows NewTrackClientE and NewAPIClientE to accept a plain
+// http base URL, such as an httptest server or a local emulator. It has no
+// effect on NewTrackClient and NewAPIClient, which never validate the URL.
+func WithInsecureURL() Option {
+	return option{insecure: true}
+}
+
+// checkedOptions summarizes a list of options.
+type checkedOptions struct {
+	// errs holds the errors of invalid options.
+	errs []error
+	// insecure and credentials report whether the options include
+	// WithInsecureURL and WithCredentialsProvider.
+	insecure    bool
+	credentials bool
+	// autoRegion reports whether the options include WithAutoRegion.
+	autoRegion bool
+}
+
+// checkOptions checks opts without making any requests. WithAutoRegion given
+// with WithURL is an error, since both choose the base URL.
+func checkOptions(opts []Option) checkedOptions {
+	var c checkedOptions
+	var url bool
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt.validate(); err != nil {
+			c.errs = append(c.errs, err)
+		}
+		if o, ok := opt.(option); ok {
+			c.insecure = c.insecure || o.insecure
+			c.credentials = c.credentials || o.credentials
+			c.autoRegion = c.autoRegion || o.auto != nil
+			url = url || o.url
+		}
+	}
+	if c.autoRegion && url {
+		c.errs = append(c.errs, errors.New("customerio: WithAutoRegion conflicts with WithURL"))
+	}
+	return c
+}
+
+// checkBaseURL reports whether raw is an absolute https URL, or an http URL
+// when insecure is set.
+func checkBaseURL(raw string, insecure bool) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("customerio: invalid url: %w", err)
+	}
+	if u.Host == "" || (u.Scheme != "https" && !(insecure && u.Scheme == "http")) {
+		if insecure {
+			return fmt.Errorf("customerio: invalid url %q: want an absolute http or https URL", raw)
+		}
+		return fmt.Errorf("customerio: invalid url %q: want an absolute https URL (use WithInsecureURL to allow http)", raw)
+	}
+	return nil
+}
+
 // TrackOption sets optional top-level fields on tracked events.
 type TrackOption func(map[string]any)
 
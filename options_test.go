@@ -1,8 +1,10 @@
 package customerio_test
 
 import (
+	"errors"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/customerio/go-customerio/v3"
@@ -129,3 +131,96 @@ func TestTrackDefaultClientAcceptsInstrumentedDefaultTransport(t *testing.T) {
 		t.Errorf("wrong default timeout. got: %s, want: %s", defaultHTTPClient.Timeout, customerio.DefaultHTTPTimeout)
 	}
 }
+
+func TestInvalidOptionPanicsOnConstruction(t *testing.T) {
+	opt := customerio.WithRegion("mars")
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected NewTrackClient to panic")
+		}
+		if msg, _ := r.(string); !strings.Contains(msg, `unknown region "mars"`) {
+			t.Errorf("wrong panic: %v", r)
+		}
+	}()
+	customerio.NewTrackClient("site_id", "api_key", opt)
+}
+
+func TestNewTrackClientE(t *testing.T) {
+	client, err := customerio.NewTrackClientE("site_id", "api_key", customerio.WithRegion(customerio.RegionEU))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.URL != customerio.RegionEU.TrackURL() {
+		t.Errorf("wrong url. got: %s, want: %s", client.URL, customerio.RegionEU.TrackURL())
+	}
+
+	_, err = customerio.NewTrackClientE("", "",
+		customerio.WithRegion("mars"),
+		customerio.WithHTTPClient(nil),
+		customerio.WithUserAgent(""),
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"siteID: missing", "apiKey: missing", `unknown region "mars"`, "nil HTTPClient", "WithUserAgent"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	var perr customerio.ParamError
+	if !errors.As(err, &perr) || perr.Param != "siteID" {
+		t.Errorf("expected siteID ParamError, got %v", err)
+	}
+}
+
+func TestNewAPIClientE(t *testing.T) {
+	client, err := customerio.NewAPIClientE("mykey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.URL != customerio.RegionUS.APIURL() {
+		t.Errorf("wrong url. got: %s, want: %s", client.URL, customerio.RegionUS.APIURL())
+	}
+
+	_, err = customerio.NewAPIClientE("", customerio.WithURL(""))
+	if err == nil || !strings.Contains(err.Error(), "key: missing") || !strings.Contains(err.Error(), "WithURL") {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestValidatingConstructorsRequireHTTPS(t *testing.T) {
+	cases := map[string]struct {
+		url      string
+		insecure bool
+		ok       bool
+	}{
+		"https":            {url: "https://example.com", ok: true},
+		"http":             {url: "http://localhost:8080"},
+		"http insecure":    {url: "http://localhost:8080", insecure: true, ok: true},
+		"relative":         {url: "/api"},
+		"no host insecure": {url: "http://", insecure: true},
+		"bad scheme":       {url: "ftp://example.com", insecure: true},
+		"unparseable":      {url: "https://exa mple.com"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			opts := []customerio.Option{customerio.WithURL(tc.url)}
+			if tc.insecure {
+				opts = append(opts, customerio.WithInsecureURL())
+			}
+
+			_, trackErr := customerio.NewTrackClientE("site_id", "api_key", opts...)
+			_, apiErr := customerio.NewAPIClientE("mykey", opts...)
+			for _, err := range []error{trackErr, apiErr} {
+				if tc.ok && err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if !tc.ok && err == nil {
+					t.Errorf("expected error for %q", tc.url)
+				}
+			}
+		})
+	}
+}
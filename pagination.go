@@ -0,0 +1,33 @@
+package customerio
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// PageOptions selects a page of results from an App API list endpoint.
+// Start is the cursor returned as Next by the previous page; leave it empty
+// to fetch the first page. A zero Limit uses the server default.
+type PageOptions struct {
+	Start string
+	Limit int
+}
+
+func (p PageOptions) values() url.Values {
+	v := url.Values{}
+	if p.Start != "" {
+		v.Set("start", p.Start)
+	}
+	if p.Limit > 0 {
+		v.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return v
+}
+
+// withQuery appends the encoded query values to requestPath, if there are any.
+func withQuery(requestPath string, v url.Values) string {
+	if encoded := v.Encode(); encoded != "" {
+		return requestPath + "?" + encoded
+	}
+	return requestPath
+}
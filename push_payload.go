@@ -0,0 +1,223 @@
+package customerio
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+)
+
+const (
+	// MaxAPNsPayloadSize is the largest iOS payload APNs delivers.
+	MaxAPNsPayloadSize = 4096
+	// MaxFCMPayloadSize is the largest Android message FCM delivers.
+	MaxFCMPayloadSize = 4096
+	// MaxFCMTTL is the longest time FCM will hold an undelivered message.
+	MaxFCMTTL = 28 * 24 * time.Hour
+)
+
+// PushPayload builds the platform-specific custom_payload of a transactional
+// push. Set it on a request with SendPushRequest.SetCustomPayload.
+type PushPayload struct {
+	IOS     *APNsPayload
+	Android *FCMPayload
+}
+
+// InterruptionLevel controls how an iOS notification interrupts the user.
+type InterruptionLevel string
+
+const (
+	InterruptionLevelPassive       InterruptionLevel = "passive"
+	InterruptionLevelActive        InterruptionLevel = "active"
+	InterruptionLevelTimeSensitive InterruptionLevel = "time-sensitive"
+	InterruptionLevelCritical      InterruptionLevel = "critical"
+)
+
+// APNsAlert is the visible content of an iOS notification.
+type APNsAlert struct {
+	Title    string `json:"title,omitempty"`
+	Subtitle string `json:"subtitle,omitempty"`
+	Body     string `json:"body,omitempty"`
+}
+
+// APNsPayload is an iOS notification sent through APNs.
+type APNsPayload struct {
+	Alert *APNsAlert
+	// Badge sets the app icon badge; zero clears it and nil leaves it alone.
+	Badge             *int
+	Sound             string
+	Category          string
+	ThreadID          string
+	MutableContent    bool
+	InterruptionLevel InterruptionLevel
+	// Data holds custom keys sent alongside the aps dictionary.
+	Data map[string]any
+}
+
+type apsDictionary struct {
+	Alert             *APNsAlert        `json:"alert,omitempty"`
+	Badge             *int              `json:"badge,omitempty"`
+	Sound             string            `json:"sound,omitempty"`
+	Category          string            `json:"category,omitempty"`
+	ThreadID          string            `json:"thread-id,omitempty"`
+	MutableContent    int               `json:"mutable-content,omitempty"`
+	InterruptionLevel InterruptionLevel `json:"interruption-level,omitempty"`
+}
+
+func (p APNsPayload) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any, len(p.Data)+1)
+	maps.Copy(out, p.Data)
+	aps := apsDictionary{
+		Alert:             p.Alert,
+		Badge:             p.Badge,
+		Sound:             p.Sound,
+		Category:          p.Category,
+		ThreadID:          p.ThreadID,
+		InterruptionLevel: p.InterruptionLevel,
+	}
+	if p.MutableContent {
+		aps.MutableContent = 1
+	}
+	out["aps"] = aps
+	return json.Marshal(out)
+}
+
+// FCMPriority is the delivery priority of an Android message.
+type FCMPriority string
+
+const (
+	FCMPriorityNormal FCMPriority = "normal"
+	FCMPriorityHigh   FCMPriority = "high"
+)
+
+// FCMNotification is the visible content of an Android notification.
+type FCMNotification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	Image string `json:"image,omitempty"`
+}
+
+// FCMPayload is an Android message sent through FCM.
+type FCMPayload struct {
+	Notification *FCMNotification
+	// Data holds custom key-value pairs delivered to the app.
+	Data      map[string]string
+	ChannelID string
+	Priority  FCMPriority
+	// TTL limits how long FCM holds an undelivered message; zero uses the
+	// FCM default.
+	TTL time.Duration
+}
+
+type fcmMessage struct {
+	Message struct {
+		Notification *FCMNotification  `json:"notification,omitempty"`
+		Data         map[string]string `json:"data,omitempty"`
+		Android      *fcmAndroid       `json:"android,omitempty"`
+	} `json:"message"`
+}
+
+type fcmAndroid struct {
+	Priority     FCMPriority `json:"priority,omitempty"`
+	TTL          string      `json:"ttl,omitempty"`
+	Notification *struct {
+		ChannelID string `json:"channel_id"`
+	} `json:"notification,omitempty"`
+}
+
+func (p FCMPayload) MarshalJSON() ([]byte, error) {
+	var m fcmMessage
+	m.Message.Notification = p.Notification
+	m.Message.Data = p.Data
+	if p.Priority != "" || p.TTL != 0 || p.ChannelID != "" {
+		a := &fcmAndroid{Priority: p.Priority}
+		if p.TTL != 0 {
+			a.TTL = fmt.Sprintf("%ds", int64(p.TTL/time.Second))
+		}
+		if p.ChannelID != "" {
+			a.Notification = &struct {
+				ChannelID string `json:"channel_id"`
+			}{p.ChannelID}
+		}
+		m.Message.Android = a
+	}
+	return json.Marshal(m)
+}
+
+func (p PushPayload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		IOS     *APNsPayload `json:"ios,omitempty"`
+		Android *FCMPayload  `json:"android,omitempty"`
+	}{p.IOS, p.Android})
+}
+
+// Validate reports every invalid field of the payload, including platform
+// payloads over MaxAPNsPayloadSize or MaxFCMPayloadSize, as a
+// *ValidationError.
+func (p PushPayload) Validate() error {
+	var f fieldErrors
+	if p.IOS == nil && p.Android == nil {
+		f.add("custom_payload", "no platform payload set")
+	}
+	if p.IOS != nil {
+		if p.IOS.Badge != nil && *p.IOS.Badge < 0 {
+			f.add("custom_payload.ios.aps.badge", "negative badge %d", *p.IOS.Badge)
+		}
+		switch p.IOS.InterruptionLevel {
+		case "", InterruptionLevelPassive, InterruptionLevelActive, InterruptionLevelTimeSensitive, InterruptionLevelCritical:
+		default:
+			f.add("custom_payload.ios.aps.interruption-level", "unknown level %q", p.IOS.InterruptionLevel)
+		}
+		if _, ok := p.IOS.Data["aps"]; ok {
+			f.add("custom_payload.ios.aps", "set in Data; use the APNsPayload fields instead")
+		}
+	}
+	if p.Android != nil {
+		switch p.Android.Priority {
+		case "", FCMPriorityNormal, FCMPriorityHigh:
+		default:
+			f.add("custom_payload.android.message.android.priority", "unknown priority %q", p.Android.Priority)
+		}
+		if p.Android.TTL < 0 || p.Android.TTL > MaxFCMTTL {
+			f.add("custom_payload.android.message.android.ttl", "%s is outside 0 to %s", p.Android.TTL, MaxFCMTTL)
+		}
+	}
+	if b, err := json.Marshal(p); err != nil {
+		f.add("custom_payload", "%v", err)
+	} else {
+		f.payloadSizes(b)
+	}
+	return f.err()
+}
+
+// payloadSizes checks the size of each platform payload in an encoded
+// custom_payload object.
+func (f *fieldErrors) payloadSizes(customPayload []byte) {
+	var platforms struct {
+		IOS     json.RawMessage `json:"ios"`
+		Android json.RawMessage `json:"android"`
+	}
+	if err := json.Unmarshal(customPayload, &platforms); err != nil {
+		f.add("custom_payload", "invalid JSON: %v", err)
+		return
+	}
+	if n := len(platforms.IOS); n > MaxAPNsPayloadSize {
+		f.add("custom_payload.ios", "%d bytes exceeds the %d byte APNs limit", n, MaxAPNsPayloadSize)
+	}
+	if n := len(platforms.Android); n > MaxFCMPayloadSize {
+		f.add("custom_payload.android", "%d bytes exceeds the %d byte FCM limit", n, MaxFCMPayloadSize)
+	}
+}
+
+// SetCustomPayload validates p and sets it as the request's CustomPayload.
+func (r *SendPushRequest) SetCustomPayload(p PushPayload) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	r.CustomPayload = b
+	return nil
+}
@@ -0,0 +1,139 @@
+package customerio_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestPushPayloadJSON(t *testing.T) {
+	badge := 3
+	payload := customerio.PushPayload{
+		IOS: &customerio.APNsPayload{
+			Alert:             &customerio.APNsAlert{Title: "Order shipped", Body: "Arriving Tuesday"},
+			Badge:             &badge,
+			Sound:             "default",
+			Category:          "ORDER",
+			ThreadID:          "order-1",
+			MutableContent:    true,
+			InterruptionLevel: customerio.InterruptionLevelTimeSensitive,
+			Data:              map[string]any{"order_id": "1"},
+		},
+		Android: &customerio.FCMPayload{
+			Notification: &customerio.FCMNotification{Title: "Order shipped", Body: "Arriving Tuesday"},
+			Data:         map[string]string{"order_id": "1"},
+			ChannelID:    "orders",
+			Priority:     customerio.FCMPriorityHigh,
+			TTL:          time.Hour,
+		},
+	}
+	var req customerio.SendPushRequest
+	if err := req.SetCustomPayload(payload); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{
+		"ios": {
+			"aps": {
+				"alert": {"title": "Order shipped", "body": "Arriving Tuesday"},
+				"badge": 3,
+				"sound": "default",
+				"category": "ORDER",
+				"thread-id": "order-1",
+				"mutable-content": 1,
+				"interruption-level": "time-sensitive"
+			},
+			"order_id": "1"
+		},
+		"android": {
+			"message": {
+				"notification": {"title": "Order shipped", "body": "Arriving Tuesday"},
+				"data": {"order_id": "1"},
+				"android": {"priority": "high", "ttl": "3600s", "notification": {"channel_id": "orders"}}
+			}
+		}
+	}`
+	var got, expected any
+	if err := json.Unmarshal(req.CustomPayload, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &expected); err != nil {
+		t.Fatal(err)
+	}
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(expected)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("got %s, want %s", gotJSON, wantJSON)
+	}
+}
+
+func TestPushPayloadOmitsUnsetFields(t *testing.T) {
+	b, err := json.Marshal(customerio.PushPayload{
+		Android: &customerio.FCMPayload{Data: map[string]string{"k": "v"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"android":{"message":{"data":{"k":"v"}}}}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
+
+func TestPushPayloadValidate(t *testing.T) {
+	negative := -1
+	cases := map[string]struct {
+		payload customerio.PushPayload
+		fields  []string
+	}{
+		"empty": {customerio.PushPayload{}, []string{"custom_payload"}},
+		"ios": {customerio.PushPayload{IOS: &customerio.APNsPayload{
+			Badge:             &negative,
+			InterruptionLevel: "loud",
+			Data:              map[string]any{"aps": map[string]any{}},
+		}}, []string{"custom_payload.ios.aps.badge", "custom_payload.ios.aps.interruption-level", "custom_payload.ios.aps"}},
+		"android": {customerio.PushPayload{Android: &customerio.FCMPayload{
+			Priority: "urgent",
+			TTL:      customerio.MaxFCMTTL + time.Second,
+		}}, []string{"custom_payload.android.message.android.priority", "custom_payload.android.message.android.ttl"}},
+		"too large": {customerio.PushPayload{
+			IOS:     &customerio.APNsPayload{Alert: &customerio.APNsAlert{Body: strings.Repeat("x", customerio.MaxAPNsPayloadSize)}},
+			Android: &customerio.FCMPayload{Data: map[string]string{"k": strings.Repeat("x", customerio.MaxFCMPayloadSize)}},
+		}, []string{"custom_payload.ios", "custom_payload.android"}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var verr *customerio.ValidationError
+			if err := tc.payload.Validate(); !errors.As(err, &verr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			var fields []string
+			for _, f := range verr.Errors {
+				fields = append(fields, f.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tc.fields, ",") {
+				t.Errorf("got fields %v, want %v", fields, tc.fields)
+			}
+
+			var req customerio.SendPushRequest
+			if err := req.SetCustomPayload(tc.payload); err == nil || req.CustomPayload != nil {
+				t.Errorf("expected SetCustomPayload to fail without setting the payload")
+			}
+		})
+	}
+}
+
+func TestSendPushValidatesCustomPayloadSize(t *testing.T) {
+	req := customerio.SendPushRequest{
+		TransactionalMessageID: "1",
+		Identifiers:            map[string]string{"id": "1"},
+		CustomPayload:          json.RawMessage(`{"ios": {"aps": {"alert": "` + strings.Repeat("x", customerio.MaxAPNsPayloadSize) + `"}}}`),
+	}
+	var ferr customerio.FieldError
+	if err := req.Validate(); !errors.As(err, &ferr) || ferr.Field != "custom_payload.ios" {
+		t.Errorf("expected custom_payload.ios error, got %v", err)
+	}
+}
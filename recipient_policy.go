@@ -0,0 +1,368 @@
+package customerio
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/mail"
+	"slices"
+	"strings"
+)
+
+// ErrRecipientNotAllowed is matched by every RecipientError.
+var ErrRecipientNotAllowed = errors.New("customerio: recipient not allowed")
+
+// RecipientError is returned when a RecipientPolicy rejects a recipient.
+type RecipientError struct {
+	// Field names the request field holding the recipient, such as "to" or
+	// "identifiers.email".
+	Field string
+	// Recipient is the rejected value.
+	Recipient string
+}
+
+func (e *RecipientError) Error() string {
+	return fmt.Sprintf("customerio: recipient %q in %s not allowed by recipient policy", e.Recipient, e.Field)
+}
+
+func (e *RecipientError) Unwrap() error { return ErrRecipientNotAllowed }
+
+// RecipientPolicy restricts who an APIClient may message, protecting
+// non-production environments from reaching real customers. It applies to
+// transactional sends and to the direct recipients of TriggerBroadcast.
+//
+// Recipients that are not allowed are rejected with a *RecipientError, or,
+// when Rewrite is set, replaced with the matching sink recipient.
+type RecipientPolicy struct {
+	// EmailDomains lists the domains, such as "example.com", whose email
+	// addresses are allowed. Matching is case-insensitive and exact, so
+	// subdomains must be listed separately.
+	EmailDomains []string
+	// Emails lists individual allowed email addresses.
+	Emails []string
+	// PhoneNumbers lists allowed SMS recipients. Spaces, dashes, dots and
+	// parentheses are ignored when comparing.
+	PhoneNumbers []string
+	// Identifiers lists allowed customer ids and cio_ids.
+	Identifiers []string
+	// DeviceTokens lists allowed custom push device tokens.
+	DeviceTokens []string
+
+	// AllowSegmentBroadcasts permits broadcasts targeting a segment or a data
+	// file, whose recipients cannot be checked. They are rejected otherwise.
+	AllowSegmentBroadcasts bool
+
+	// Rewrite replaces disallowed recipients with the sink recipients below
+	// instead of rejecting the request. A disallowed recipient whose sink is
+	// empty is still rejected. A message rewritten away from the identified
+	// profile, or from a push device token, goes to the profile identified by
+	// SinkIdentifier, or for email without one, by SinkEmail.
+	Rewrite        bool
+	SinkEmail      string
+	SinkPhone      string
+	SinkIdentifier string
+
+	// Logger receives a message for every rewritten recipient. Defaults to
+	// slog.Default().
+	Logger *slog.Logger
+}
+
+// WithRecipientPolicy makes App API clients enforce p on every transactional
+// send and broadcast trigger. It has no effect on Track clients.
+func WithRecipientPolicy(p RecipientPolicy) Option {
+	return option{
+		api: func(a *APIClient) {
+			a.recipients = &p
+		},
+	}
+}
+
+func (p *RecipientPolicy) emailAllowed(addr string) bool {
+	addr = strings.ToLower(strings.TrimSpace(addr))
+	if slices.ContainsFunc(p.Emails, func(e string) bool { return strings.EqualFold(e, addr) }) {
+		return true
+	}
+	at := strings.LastIndexByte(addr, '@')
+	return at >= 0 && slices.ContainsFunc(p.EmailDomains, func(d string) bool {
+		return strings.EqualFold(d, addr[at+1:])
+	})
+}
+
+func normalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" -.()", r) {
+			return -1
+		}
+		return r
+	}, phone)
+}
+
+func (p *RecipientPolicy) phoneAllowed(phone string) bool {
+	phone = normalizePhone(phone)
+	return slices.ContainsFunc(p.PhoneNumbers, func(n string) bool { return normalizePhone(n) == phone })
+}
+
+func (p *RecipientPolicy) identifierAllowed(id string) bool {
+	return slices.Contains(p.Identifiers, id)
+}
+
+// rewrite returns sink as the replacement for a disallowed recipient, or a
+// *RecipientError if rewriting is disabled or sink is empty.
+func (p *RecipientPolicy) rewrite(field, recipient, sink string) (string, error) {
+	if !p.Rewrite || sink == "" {
+		return "", &RecipientError{Field: field, Recipient: recipient}
+	}
+	logger := p.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Info("customerio: rewrote disallowed recipient", "field", field, "recipient", recipient, "sink", sink)
+	return sink, nil
+}
+
+// addressList checks every address in a comma-separated email header value,
+// replacing disallowed ones with the sink.
+func (p *RecipientPolicy) addressList(field, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	addrs, err := mail.ParseAddressList(value)
+	if err != nil {
+		return p.rewrite(field, value, p.SinkEmail)
+	}
+	var out []string
+	for _, a := range addrs {
+		if p.emailAllowed(a.Address) {
+			if a.Name == "" {
+				out = append(out, a.Address)
+			} else {
+				out = append(out, a.String())
+			}
+			continue
+		}
+		sink, err := p.rewrite(field, a.Address, p.SinkEmail)
+		if err != nil {
+			return "", err
+		}
+		if !slices.Contains(out, sink) {
+			out = append(out, sink)
+		}
+	}
+	return strings.Join(out, ", "), nil
+}
+
+// identifiersAllowed reports whether every identifier in ids is allowed,
+// returning the field and value of the first one that is not.
+func (p *RecipientPolicy) identifiersAllowed(ids map[string]string) (field, value string, ok bool) {
+	keys := make([]string, 0, len(ids))
+	for k := range ids {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		v := ids[k]
+		allowed := p.identifierAllowed(v)
+		if IdentifierType(k) == IdentifierTypeEmail {
+			allowed = p.emailAllowed(v)
+		}
+		if !allowed {
+			return "identifiers." + k, v, false
+		}
+	}
+	return "", "", true
+}
+
+// sinkProfile returns the identifiers of the sink profile that replaces a
+// disallowed one: SinkIdentifier, or, for email when SinkIdentifier is
+// empty, the SinkEmail address.
+func (p *RecipientPolicy) sinkProfile(field, value string, email bool) (map[string]string, error) {
+	if email && p.SinkIdentifier == "" {
+		sink, err := p.rewrite(field, value, p.SinkEmail)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{string(IdentifierTypeEmail): sink}, nil
+	}
+	sink, err := p.rewrite(field, value, p.SinkIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{string(IdentifierTypeID): sink}, nil
+}
+
+// sinkIdentifiers checks the identifiers of a message that is delivered to
+// the identified profile, replacing them with the sink identifier.
+func (p *RecipientPolicy) sinkIdentifiers(ids map[string]string) (map[string]string, error) {
+	field, value, ok := p.identifiersAllowed(ids)
+	if ok {
+		return ids, nil
+	}
+	return p.sinkProfile(field, value, false)
+}
+
+// pushTargets are the SendPushRequest.To values that select devices of the
+// identified profile rather than naming a device token.
+var pushTargets = []string{"", "all", "last_used"}
+
+// transactional returns req, or a rewritten copy of it, if its recipients
+// are allowed. The caller's request is never modified.
+func (p *RecipientPolicy) transactional(req any) (any, error) {
+	var err error
+	switch r := req.(type) {
+	case *SendEmailRequest:
+		out := *r
+		if out.To == "" {
+			// Without To the message goes to the identified profile, which
+			// is replaced by the sink profile along with its address.
+			if field, value, ok := p.identifiersAllowed(out.Identifiers); !ok {
+				if out.To, err = p.rewrite(field, value, p.SinkEmail); err != nil {
+					return nil, err
+				}
+				if out.Identifiers, err = p.sinkProfile(field, value, true); err != nil {
+					return nil, err
+				}
+			}
+		} else if out.To, err = p.addressList("to", out.To); err != nil {
+			return nil, err
+		}
+		if out.CC, err = p.addressList("cc", out.CC); err != nil {
+			return nil, err
+		}
+		if out.BCC, err = p.addressList("bcc", out.BCC); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	case *SendSMSRequest:
+		out := *r
+		profile := out.To == ""
+		field, value, ok := "to", out.To, p.phoneAllowed(out.To)
+		if profile {
+			field, value, ok = p.identifiersAllowed(out.Identifiers)
+		}
+		if !ok {
+			if out.To, err = p.rewrite(field, value, p.SinkPhone); err != nil {
+				return nil, err
+			}
+		}
+		if !ok && profile {
+			if out.Identifiers, err = p.sinkProfile(field, value, false); err != nil {
+				return nil, err
+			}
+		}
+		return &out, nil
+	case *SendPushRequest:
+		out := *r
+		if out.Device != nil {
+			// A custom device is messaged directly, whoever is identified.
+			if slices.Contains(p.DeviceTokens, out.Device.Token) {
+				return &out, nil
+			}
+			sink, err := p.rewrite("custom_device.token", out.Device.Token, p.SinkIdentifier)
+			if err != nil {
+				return nil, err
+			}
+			out.Identifiers, out.Device, out.To = map[string]string{string(IdentifierTypeID): sink}, nil, ""
+			return &out, nil
+		}
+		if !slices.Contains(pushTargets, out.To) && !slices.Contains(p.DeviceTokens, out.To) {
+			// A device token in To is messaged directly too.
+			sink, err := p.rewrite("to", out.To, p.SinkIdentifier)
+			if err != nil {
+				return nil, err
+			}
+			out.Identifiers, out.To = map[string]string{string(IdentifierTypeID): sink}, ""
+			return &out, nil
+		}
+		if out.Identifiers, err = p.sinkIdentifiers(out.Identifiers); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	case *SendInAppRequest:
+		out := *r
+		if out.Identifiers, err = p.sinkIdentifiers(out.Identifiers); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	case *SendInboxMessageRequest:
+		out := *r
+		if out.Identifiers, err = p.sinkIdentifiers(out.Identifiers); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	default:
+		return req, nil
+	}
+}
+
+// userDataField returns u[key] as a string, or "" if it is not set.
+func userDataField(u map[string]any, key string) string {
+	v, ok := u[key]
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// broadcast returns recipients, or a rewritten copy of them, if every direct
+// recipient is allowed.
+func (p *RecipientPolicy) broadcast(r BroadcastRecipients) (BroadcastRecipients, error) {
+	if r.Segment != nil || r.DataFileURL != "" {
+		if !p.AllowSegmentBroadcasts {
+			field, value := "segment", fmt.Sprint(r.Segment)
+			if r.DataFileURL != "" {
+				field, value = "data_file_url", r.DataFileURL
+			}
+			return r, &RecipientError{Field: field, Recipient: value}
+		}
+	}
+
+	filter := func(field string, in []string, allowed func(string) bool, sink string) ([]string, error) {
+		var out []string
+		for _, v := range in {
+			if !allowed(v) {
+				var err error
+				if v, err = p.rewrite(field, v, sink); err != nil {
+					return nil, err
+				}
+			}
+			if !slices.Contains(out, v) {
+				out = append(out, v)
+			}
+		}
+		return out, nil
+	}
+
+	var err error
+	if r.Ids, err = filter("ids", r.Ids, p.identifierAllowed, p.SinkIdentifier); err != nil {
+		return r, err
+	}
+	if r.Emails, err = filter("emails", r.Emails, p.emailAllowed, p.SinkEmail); err != nil {
+		return r, err
+	}
+	if r.PerUserData != nil {
+		out := make([]map[string]any, 0, len(r.PerUserData))
+		for _, u := range r.PerUserData {
+			id, email := userDataField(u, "id"), userDataField(u, "email")
+			switch {
+			case id != "" && p.identifierAllowed(id), id == "" && email != "" && p.emailAllowed(email):
+				out = append(out, u)
+				continue
+			}
+			field, value, sinkKey, sink := "per_user_data.id", id, "id", p.SinkIdentifier
+			if id == "" {
+				field, value, sinkKey, sink = "per_user_data.email", email, "email", p.SinkEmail
+			}
+			if sink, err = p.rewrite(field, value, sink); err != nil {
+				return r, err
+			}
+			rewritten := maps.Clone(u)
+			delete(rewritten, "id")
+			delete(rewritten, "email")
+			rewritten[sinkKey] = sink
+			out = append(out, rewritten)
+		}
+		r.PerUserData = out
+	}
+	return r, nil
+}
@@ -0,0 +1,237 @@
+package customerio_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+// policyServer returns an App API client enforcing p, and a function
+// returning the JSON body of the last request its server received.
+func policyServer(t *testing.T, p customerio.RecipientPolicy) (*customerio.APIClient, func() map[string]any) {
+	t.Helper()
+	var last map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		last = nil
+		_ = json.NewDecoder(req.Body).Decode(&last)
+		_, _ = w.Write([]byte(`{"delivery_id": "d", "queued_at": 1, "id": 1}`))
+	}))
+	t.Cleanup(srv.Close)
+	return customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL), customerio.WithRecipientPolicy(p)), func() map[string]any { return last }
+}
+
+var testPolicy = customerio.RecipientPolicy{
+	EmailDomains: []string{"example.com"},
+	Emails:       []string{"qa@gmail.com"},
+	PhoneNumbers: []string{"+1 (555) 010-0000"},
+	Identifiers:  []string{"test-1"},
+	DeviceTokens: []string{"test-token"},
+}
+
+func TestRecipientPolicyRejects(t *testing.T) {
+	api, last := policyServer(t, testPolicy)
+	ctx := context.Background()
+
+	cases := map[string]struct {
+		send  func() error
+		field string
+	}{
+		"email to": {func() error {
+			_, err := api.SendEmail(ctx, &customerio.SendEmailRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "test-1"}, To: "Joe <joe@real.com>"})
+			return err
+		}, "to"},
+		"email bcc": {func() error {
+			_, err := api.SendEmail(ctx, &customerio.SendEmailRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "test-1"}, To: "a@example.com", BCC: "qa@gmail.com, b@real.com"})
+			return err
+		}, "bcc"},
+		"email identifier": {func() error {
+			_, err := api.SendEmail(ctx, &customerio.SendEmailRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"email": "joe@real.com"}})
+			return err
+		}, "identifiers.email"},
+		"sms to": {func() error {
+			_, err := api.SendSMS(ctx, &customerio.SendSMSRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "test-1"}, To: "+15550109999"})
+			return err
+		}, "to"},
+		"push device": {func() error {
+			_, err := api.SendPush(ctx, &customerio.SendPushRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "test-1"}, Device: &customerio.Device{Token: "real"}})
+			return err
+		}, "custom_device.token"},
+		"push to": {func() error {
+			_, err := api.SendPush(ctx, &customerio.SendPushRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "test-1"}, To: "real"})
+			return err
+		}, "to"},
+		"in-app identifier": {func() error {
+			_, err := api.SendInApp(ctx, &customerio.SendInAppRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "42"}})
+			return err
+		}, "identifiers.id"},
+		"broadcast ids": {func() error {
+			_, err := api.TriggerBroadcast(ctx, 1, nil, customerio.BroadcastRecipients{Ids: []string{"test-1", "42"}}, customerio.BroadcastOptions{})
+			return err
+		}, "ids"},
+		"broadcast segment": {func() error {
+			_, err := api.TriggerBroadcast(ctx, 1, nil, customerio.BroadcastRecipients{Segment: map[string]any{"id": 1}}, customerio.BroadcastOptions{})
+			return err
+		}, "segment"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.send()
+			var rerr *customerio.RecipientError
+			if !errors.As(err, &rerr) || !errors.Is(err, customerio.ErrRecipientNotAllowed) {
+				t.Fatalf("expected RecipientError, got %v", err)
+			}
+			if rerr.Field != tc.field {
+				t.Errorf("got field %q, want %q", rerr.Field, tc.field)
+			}
+		})
+	}
+	if last() != nil {
+		t.Errorf("expected no requests, got %v", last())
+	}
+}
+
+func TestRecipientPolicyAllows(t *testing.T) {
+	api, last := policyServer(t, testPolicy)
+	ctx := context.Background()
+
+	if _, err := api.SendEmail(ctx, &customerio.SendEmailRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "test-1"}, To: "Joe <joe@EXAMPLE.com>, qa@gmail.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.SendSMS(ctx, &customerio.SendSMSRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "test-1"}, To: "+15550100000"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.SendPush(ctx, &customerio.SendPushRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "42"}, Device: &customerio.Device{Token: "test-token"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, to := range []string{"all", "test-token"} {
+		if _, err := api.SendPush(ctx, &customerio.SendPushRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "test-1"}, To: to}); err != nil {
+			t.Fatal(err)
+		}
+		if got := last()["to"]; got != to {
+			t.Errorf("got push to %v, want %s", got, to)
+		}
+	}
+	if _, err := api.TriggerBroadcast(ctx, 1, nil, customerio.BroadcastRecipients{PerUserData: []map[string]any{{"id": "test-1"}, {"email": "a@example.com"}}}, customerio.BroadcastOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := last()["per_user_data"]; len(got.([]any)) != 2 {
+		t.Errorf("unexpected per_user_data %v", got)
+	}
+}
+
+func TestRecipientPolicyRewrites(t *testing.T) {
+	var logs bytes.Buffer
+	p := testPolicy
+	p.Rewrite = true
+	p.SinkEmail = "sink@example.com"
+	p.SinkPhone = "+15550100000"
+	p.SinkIdentifier = "test-1"
+	p.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+	api, last := policyServer(t, p)
+	ctx := context.Background()
+
+	req := &customerio.SendEmailRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "42"}, To: "joe@real.com, a@example.com", CC: "ann@real.com"}
+	if _, err := api.SendEmail(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if got := last(); got["to"] != "sink@example.com, a@example.com" || got["cc"] != "sink@example.com" {
+		t.Errorf("unexpected rewrite %v", got)
+	}
+	if req.To != "joe@real.com, a@example.com" {
+		t.Errorf("caller's request was modified: %q", req.To)
+	}
+
+	if _, err := api.SendEmail(ctx, &customerio.SendEmailRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "42"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := last(); got["to"] != "sink@example.com" || got["identifiers"].(map[string]any)["id"] != "test-1" {
+		t.Errorf("expected profile email and identifiers rewritten to sink, got %v", got)
+	}
+
+	if _, err := api.SendSMS(ctx, &customerio.SendSMSRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "test-1"}, To: "+15550109999"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := last()["to"]; got != p.SinkPhone {
+		t.Errorf("unexpected sms to %v", got)
+	}
+
+	if _, err := api.SendPush(ctx, &customerio.SendPushRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "42"}, Device: &customerio.Device{Token: "real"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := last(); got["custom_device"] != nil || got["identifiers"].(map[string]any)["id"] != "test-1" {
+		t.Errorf("unexpected push rewrite %v", got)
+	}
+
+	if _, err := api.SendPush(ctx, &customerio.SendPushRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "test-1"}, To: "real"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := last(); got["to"] != nil || got["identifiers"].(map[string]any)["id"] != "test-1" {
+		t.Errorf("unexpected push to rewrite %v", got)
+	}
+
+	recipients := customerio.BroadcastRecipients{Emails: []string{"a@real.com", "b@real.com", "c@example.com"}}
+	if _, err := api.TriggerBroadcast(ctx, 1, nil, recipients, customerio.BroadcastOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := json.Marshal(last()["emails"]); string(got) != `["sink@example.com","c@example.com"]` {
+		t.Errorf("unexpected broadcast emails %s", got)
+	}
+
+	recipients = customerio.BroadcastRecipients{PerUserData: []map[string]any{{"id": 42, "data": map[string]any{"x": 1}}}}
+	if _, err := api.TriggerBroadcast(ctx, 1, nil, recipients, customerio.BroadcastOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := json.Marshal(last()["per_user_data"]); string(got) != `[{"data":{"x":1},"id":"test-1"}]` {
+		t.Errorf("unexpected per_user_data %s", got)
+	}
+
+	if n := strings.Count(logs.String(), "rewrote disallowed recipient"); n != 10 {
+		t.Errorf("expected 10 logged rewrites, got %d:\n%s", n, logs.String())
+	}
+}
+
+func TestRecipientPolicyRewritesProfile(t *testing.T) {
+	p := testPolicy
+	p.Rewrite = true
+	p.SinkEmail = "sink@example.com"
+	p.SinkPhone = "+15550100000"
+	p.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	api, last := policyServer(t, p)
+	ctx := context.Background()
+
+	// Without SinkIdentifier, email falls back to the sink address.
+	if _, err := api.SendEmail(ctx, &customerio.SendEmailRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "42"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := json.Marshal(last()["identifiers"]); last()["to"] != "sink@example.com" || string(got) != `{"email":"sink@example.com"}` {
+		t.Errorf("unexpected email rewrite %v", last())
+	}
+
+	// Other channels cannot replace the profile and are rejected.
+	_, err := api.SendSMS(ctx, &customerio.SendSMSRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "42"}})
+	var rerr *customerio.RecipientError
+	if !errors.As(err, &rerr) || rerr.Field != "identifiers.id" {
+		t.Errorf("expected sms profile rejected, got %v", err)
+	}
+	_, err = api.SendPush(ctx, &customerio.SendPushRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "test-1"}, To: "real"})
+	if !errors.As(err, &rerr) || rerr.Field != "to" {
+		t.Errorf("expected push token rejected, got %v", err)
+	}
+
+	// An allowed To keeps the identified profile.
+	if _, err := api.SendSMS(ctx, &customerio.SendSMSRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "42"}, To: "+15550100000"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := last()["identifiers"].(map[string]any)["id"]; got != "42" {
+		t.Errorf("unexpected identifiers %v", got)
+	}
+}
@@ -0,0 +1,130 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// AccountRegion describes where a workspace's data is hosted, as reported by
+// the Track API.
+type AccountRegion struct {
+	// URL is the Track API base URL for the workspace.
+	URL string `json:"url"`
+	// DataCenter is "us" or "eu".
+	DataCenter    string `json:"data_center"`
+	EnvironmentID int    `json:"environment_id"`
+}
+
+// Region returns the Region for the account's data center.
+func (a AccountRegion) Region() (Region, error) {
+	switch r := Region(a.DataCenter); r {
+	case RegionUS, RegionEU:
+		return r, nil
+	default:
+		return "", fmt.Errorf("customerio: unknown data center %q", a.DataCenter)
+	}
+}
+
+// AccountRegion looks up the region of the workspace the client's
+// credentials belong to. The lookup is always made against the client's
+// configured URL; use DetectRegion for a cached lookup.
+func (c *CustomerIO) AccountRegion(ctx context.Context) (*AccountRegion, error) {
+	body, err := c.requestBody(ctx, "GET", c.URL+"/api/v1/accounts/region", nil)
+	if err != nil {
+		return nil, err
+	}
+	var region AccountRegion
+	if err := json.Unmarshal(body, &region); err != nil {
+		return nil, err
+	}
+	return &region, nil
+}
+
+var detectedRegions sync.Map // regionCacheKey -> Region
+
+type regionCacheKey struct {
+	url, siteID, apiKey string
+}
+
+// DetectRegion returns the region of the workspace identified by a Tracking
+// Site ID and API Key pair. opts configure the Track client used for the
+// lookup. Successful lookups are cached for the life of the process.
+func DetectRegion(ctx context.Context, siteID, apiKey string, opts ...Option) (Region, error) {
+	if siteID == "" {
+		return "", ParamError{Param: "siteID"}
+	}
+	if apiKey == "" {
+		return "", ParamError{Param: "apiKey"}
+	}
+	if checked := checkOptions(opts); len(checked.errs) > 0 {
+		return "", checked.errs[0]
+	} else if checked.autoRegion {
+		return "", errors.New("customerio: DetectRegion called with WithAutoRegion")
+	}
+	c := newTrackClient(siteID, apiKey, opts)
+
+	key := regionCacheKey{url: c.URL, siteID: siteID, apiKey: apiKey}
+	if r, ok := detectedRegions.Load(key); ok {
+		return r.(Region), nil
+	}
+	account, err := c.AccountRegion(ctx)
+	if err != nil {
+		return "", fmt.Errorf("customerio: detecting region: %w", err)
+	}
+	r, err := account.Region()
+	if err != nil {
+		return "", err
+	}
+	detectedRegions.Store(key, r)
+	return r, nil
+}
+
+// autoRegion holds the arguments of WithAutoRegion until a client is built.
+type autoRegion struct {
+	siteID, apiKey string
+	opts           []Option
+}
+
+// WithAutoRegion configures clients for the workspace's region, as WithRegion
+// does, detecting it with DetectRegion when the client is built. It can only
+// be used with the constructors and Config and Registry methods that return
+// detection errors; NewTrackClientCtx, NewAPIClientCtx, Config.TrackClientCtx
+// and Config.APIClientCtx take the context for the lookup, and the others use
+// context.Background. NewTrackClient and NewAPIClient panic if given it.
+// Building a client also fails if WithRegion is given with a different
+// region, or if WithURL is given at all.
+//
+// App API clients need the workspace's Track credentials for detection.
+func WithAutoRegion(siteID, apiKey string, opts ...Option) Option {
+	return option{auto: &autoRegion{siteID: siteID, apiKey: apiKey, opts: opts}}
+}
+
+// detectRegions returns opts with every WithAutoRegion replaced by WithRegion
+// for the region detected using ctx. A detected region that differs from one
+// given with WithRegion is an error.
+func detectRegions(ctx context.Context, opts []Option) ([]Option, error) {
+	out := make([]Option, len(opts))
+	var configured, detected Region
+	for i, opt := range opts {
+		out[i] = opt
+		o, ok := opt.(option)
+		switch {
+		case !ok:
+		case o.auto != nil:
+			r, err := DetectRegion(ctx, o.auto.siteID, o.auto.apiKey, o.auto.opts...)
+			if err != nil {
+				return nil, err
+			}
+			out[i], detected = WithRegion(r), r
+		case o.region != "":
+			configured = o.region
+		}
+	}
+	if configured != "" && detected != "" && configured != detected {
+		return nil, fmt.Errorf("customerio: configured region %q does not match detected region %q", configured, detected)
+	}
+	return out, nil
+}
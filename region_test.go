@@ -0,0 +1,204 @@
+package customerio_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func regionServer(t *testing.T, dataCenter string) (string, *int) {
+	t.Helper()
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		if req.Method != "GET" || req.URL.Path != "/api/v1/accounts/region" {
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		}
+		if site, key, ok := req.BasicAuth(); !ok || site != "siteid" || key != "apikey" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"url": "https://track-` + dataCenter + `.customer.io", "data_center": "` + dataCenter + `", "environment_id": 12}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, &requests
+}
+
+func TestAccountRegion(t *testing.T) {
+	url, _ := regionServer(t, "eu")
+	track := customerio.NewTrackClient("siteid", "apikey", customerio.WithURL(url))
+
+	got, err := track.AccountRegion(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := customerio.AccountRegion{URL: "https://track-eu.customer.io", DataCenter: "eu", EnvironmentID: 12}
+	if *got != want {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+	if r, err := got.Region(); err != nil || r != customerio.RegionEU {
+		t.Errorf("got region %q, %v", r, err)
+	}
+	if _, err := (customerio.AccountRegion{DataCenter: "ap"}).Region(); err == nil {
+		t.Error("expected error for unknown data center")
+	}
+}
+
+func TestDetectRegionCachesResult(t *testing.T) {
+	url, requests := regionServer(t, "eu")
+	ctx := context.Background()
+
+	for range 2 {
+		r, err := customerio.DetectRegion(ctx, "siteid", "apikey", customerio.WithURL(url))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r != customerio.RegionEU {
+			t.Errorf("got region %q", r)
+		}
+	}
+	if *requests != 1 {
+		t.Errorf("expected 1 request, got %d", *requests)
+	}
+
+	if _, err := customerio.DetectRegion(ctx, "siteid", "wrong", customerio.WithURL(url)); err == nil {
+		t.Error("expected error for bad credentials")
+	}
+	if _, err := customerio.DetectRegion(ctx, "", "apikey"); err == nil {
+		t.Error("expected error for missing site id")
+	}
+}
+
+func TestWithAutoRegion(t *testing.T) {
+	url, requests := regionServer(t, "eu")
+	auto := customerio.WithAutoRegion("siteid", "apikey", customerio.WithURL(url))
+	if *requests != 0 {
+		t.Errorf("expected no request until a client is built, got %d", *requests)
+	}
+
+	track, err := customerio.NewTrackClientE("siteid", "apikey", auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if track.URL != customerio.RegionEU.TrackURL() {
+		t.Errorf("wrong track url %s", track.URL)
+	}
+	api, err := customerio.NewAPIClientE("key", auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.URL != customerio.RegionEU.APIURL() {
+		t.Errorf("wrong api url %s", api.URL)
+	}
+
+	if _, err := customerio.NewAPIClientE("key", auto, customerio.WithRegion(customerio.RegionEU)); err != nil {
+		t.Errorf("unexpected error for matching region: %v", err)
+	}
+	_, err = customerio.NewAPIClientE("key", customerio.WithRegion(customerio.RegionUS), auto)
+	if err == nil || !strings.Contains(err.Error(), `does not match detected region "eu"`) {
+		t.Errorf("expected mismatch error, got %v", err)
+	}
+
+	failed := customerio.WithAutoRegion("siteid", "wrong", customerio.WithURL(url))
+	if _, err := customerio.NewTrackClientE("siteid", "apikey", failed); err == nil {
+		t.Error("expected detection error")
+	}
+}
+
+func TestWithAutoRegionUsesConstructorContext(t *testing.T) {
+	url, requests := regionServer(t, "eu")
+	auto := customerio.WithAutoRegion("siteid", "apikey", customerio.WithURL(url))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := customerio.NewTrackClientCtx(ctx, "siteid", "apikey", auto); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled detection from NewTrackClientCtx, got %v", err)
+	}
+	if _, err := customerio.NewAPIClientCtx(ctx, "key", auto); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled detection from NewAPIClientCtx, got %v", err)
+	}
+	config := customerio.Config{SiteID: "siteid", TrackAPIKey: "apikey", AppAPIKey: "key"}
+	if _, err := config.TrackClientCtx(ctx, auto); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled detection from TrackClientCtx, got %v", err)
+	}
+	if *requests != 0 {
+		t.Errorf("expected no requests with a canceled context, got %d", *requests)
+	}
+
+	api, err := config.APIClientCtx(context.Background(), auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.URL != customerio.RegionEU.APIURL() {
+		t.Errorf("wrong api url %s", api.URL)
+	}
+}
+
+func TestWithAutoRegionRequiresErrorConstructors(t *testing.T) {
+	auto := customerio.WithAutoRegion("siteid", "apikey")
+	for name, build := range map[string]func(){
+		"track": func() { customerio.NewTrackClient("siteid", "apikey", auto) },
+		"api":   func() { customerio.NewAPIClient("key", auto) },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "WithAutoRegion requires") {
+					t.Errorf("%s: expected a panic naming the error constructor, got %v", name, r)
+				}
+			}()
+			build()
+		}()
+	}
+}
+
+func TestWithAutoRegionConflictsWithURL(t *testing.T) {
+	url, requests := regionServer(t, "us")
+	auto := customerio.WithAutoRegion("siteid", "apikey", customerio.WithURL(url))
+
+	for _, opts := range [][]customerio.Option{
+		{auto, customerio.WithURL("https://example.com")},
+		{customerio.WithURL("https://example.com"), auto},
+	} {
+		if _, err := customerio.NewTrackClientE("siteid", "apikey", opts...); err == nil || !strings.Contains(err.Error(), "conflicts with WithURL") {
+			t.Errorf("expected a conflict error, got %v", err)
+		}
+	}
+	if _, err := (customerio.Config{SiteID: "siteid", TrackAPIKey: "apikey", URL: "https://example.com"}).TrackClient(auto); err == nil {
+		t.Error("expected a conflict error with Config.URL")
+	}
+	if *requests != 0 {
+		t.Errorf("expected no detection for conflicting options, got %d requests", *requests)
+	}
+}
+
+func TestConfigAutoRegionMismatch(t *testing.T) {
+	url, _ := regionServer(t, "us")
+	auto := customerio.WithAutoRegion("siteid", "apikey", customerio.WithURL(url))
+	config := customerio.Config{SiteID: "siteid", TrackAPIKey: "apikey", AppAPIKey: "key", Region: customerio.RegionEU}
+
+	if _, err := config.TrackClient(auto); err == nil || !strings.Contains(err.Error(), `configured region "eu" does not match detected region "us"`) {
+		t.Errorf("expected mismatch error from TrackClient, got %v", err)
+	}
+	if _, err := config.APIClient(auto); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected mismatch error from APIClient, got %v", err)
+	}
+	_, err := customerio.NewRegistry(customerio.Workspace{Name: "eu", Config: config, Options: []customerio.Option{auto}})
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected mismatch error from Registry.Add, got %v", err)
+	}
+
+	config.Region = customerio.RegionUS
+	track, err := config.TrackClient(auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if track.URL != customerio.RegionUS.TrackURL() {
+		t.Errorf("wrong track url %s", track.URL)
+	}
+}
@@ -0,0 +1,259 @@
+package customerio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrUnknownWorkspace is returned when a Registry has no workspace with
+	// the requested name.
+	ErrUnknownWorkspace = errors.New("customerio: unknown workspace")
+	// ErrNoWorkspace is returned when a Registry is asked for the workspace
+	// of a context that does not carry one.
+	ErrNoWorkspace = errors.New("customerio: no workspace in context")
+)
+
+// Workspace describes one named workspace in a Registry.
+type Workspace struct {
+	// Name identifies the workspace, such as "brand-a/production".
+	Name string
+	// Config holds the workspace's credentials and endpoint settings. A Track
+	// client is created if SiteID and TrackAPIKey are set, and an App API
+	// client if AppAPIKey is set.
+	Config Config
+	// Options are applied to the workspace's clients after Config's settings.
+	Options []Option
+}
+
+type workspaceClients struct {
+	track *CustomerIO
+	api   *APIClient
+}
+
+// Registry holds the clients of several named workspaces and routes calls to
+// them by name. A Registry is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	workspaces map[string]workspaceClients
+}
+
+// NewRegistry returns a Registry holding the given workspaces.
+func NewRegistry(workspaces ...Workspace) (*Registry, error) {
+	r := &Registry{workspaces: make(map[string]workspaceClients)}
+	var errs []error
+	for _, w := range workspaces {
+		if err := r.Add(w); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return r, nil
+}
+
+// Add creates clients for w and registers them, replacing any workspace with
+// the same name.
+func (r *Registry) Add(w Workspace) error {
+	return r.AddCtx(context.Background(), w)
+}
+
+// AddCtx is like Add but uses ctx to detect the region for WithAutoRegion.
+func (r *Registry) AddCtx(ctx context.Context, w Workspace) error {
+	if w.Name == "" {
+		return ParamError{Param: "Name"}
+	}
+	var clients workspaceClients
+	var errs []error
+	if w.Config.SiteID != "" || w.Config.TrackAPIKey != "" {
+		track, err := w.Config.TrackClientCtx(ctx, w.Options...)
+		clients.track = track
+		errs = append(errs, err)
+	}
+	if w.Config.AppAPIKey != "" {
+		api, err := w.Config.APIClientCtx(ctx, w.Options...)
+		clients.api = api
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("customerio: workspace %q: %w", w.Name, err)
+	}
+	if clients.track == nil && clients.api == nil {
+		return fmt.Errorf("customerio: workspace %q: no credentials", w.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workspaces[w.Name] = clients
+	return nil
+}
+
+// Remove unregisters the named workspace.
+func (r *Registry) Remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.workspaces, name)
+}
+
+// Names returns the names of every registered workspace in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.workspaces))
+	for name := range r.workspaces {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// trackNames returns the names of every workspace with a Track client in
+// sorted order.
+func (r *Registry) trackNames() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var names []string
+	for name, clients := range r.workspaces {
+		if clients.track != nil {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (r *Registry) clients(name string) (workspaceClients, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	clients, ok := r.workspaces[name]
+	if !ok {
+		return clients, fmt.Errorf("%w %q", ErrUnknownWorkspace, name)
+	}
+	return clients, nil
+}
+
+// TrackClient returns the Track client of the named workspace.
+func (r *Registry) TrackClient(name string) (*CustomerIO, error) {
+	clients, err := r.clients(name)
+	if err != nil {
+		return nil, err
+	}
+	if clients.track == nil {
+		return nil, fmt.Errorf("customerio: workspace %q has no Track API credentials", name)
+	}
+	return clients.track, nil
+}
+
+// APIClient returns the App API client of the named workspace.
+func (r *Registry) APIClient(name string) (*APIClient, error) {
+	clients, err := r.clients(name)
+	if err != nil {
+		return nil, err
+	}
+	if clients.api == nil {
+		return nil, fmt.Errorf("customerio: workspace %q has no App API credentials", name)
+	}
+	return clients.api, nil
+}
+
+type workspaceKey struct{}
+
+// ContextWithWorkspace returns a copy of ctx that routes Registry calls to the
+// named workspace.
+func ContextWithWorkspace(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, name)
+}
+
+// WorkspaceFromContext returns the workspace name stored in ctx by
+// ContextWithWorkspace.
+func WorkspaceFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(workspaceKey{}).(string)
+	return name, ok
+}
+
+// TrackClientFor returns the Track client of the workspace named in ctx.
+func (r *Registry) TrackClientFor(ctx context.Context) (*CustomerIO, error) {
+	name, ok := WorkspaceFromContext(ctx)
+	if !ok {
+		return nil, ErrNoWorkspace
+	}
+	return r.TrackClient(name)
+}
+
+// APIClientFor returns the App API client of the workspace named in ctx.
+func (r *Registry) APIClientFor(ctx context.Context) (*APIClient, error) {
+	name, ok := WorkspaceFromContext(ctx)
+	if !ok {
+		return nil, ErrNoWorkspace
+	}
+	return r.APIClient(name)
+}
+
+// WorkspaceResult is the outcome of a fanned-out call in one workspace.
+type WorkspaceResult struct {
+	Workspace string
+	Err       error
+}
+
+// WorkspaceResults holds the outcome of a fanned-out call in every workspace
+// it was sent to, in the order the workspaces were given.
+type WorkspaceResults []WorkspaceResult
+
+// Err returns the errors of every failed workspace joined together, each
+// prefixed with the workspace name, or nil if all succeeded.
+func (rs WorkspaceResults) Err() error {
+	var errs []error
+	for _, res := range rs {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Workspace, res.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FanOut calls fn concurrently with the Track client of each named
+// workspace, or of every workspace with a Track client if names is empty, and
+// reports the result of each call. The context passed to fn names the
+// workspace.
+func (r *Registry) FanOut(ctx context.Context, names []string, fn func(context.Context, *CustomerIO) error) WorkspaceResults {
+	if len(names) == 0 {
+		names = r.trackNames()
+	}
+	results := make(WorkspaceResults, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		results[i].Workspace = name
+		client, err := r.TrackClient(name)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].Err = fn(ContextWithWorkspace(ctx, name), client)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// IdentifyAll identifies a customer in each named workspace, or in every
+// workspace with a Track client if names is empty.
+func (r *Registry) IdentifyAll(ctx context.Context, names []string, customerID string, attributes map[string]any) WorkspaceResults {
+	return r.FanOut(ctx, names, func(ctx context.Context, c *CustomerIO) error {
+		return c.IdentifyCtx(ctx, customerID, attributes)
+	})
+}
+
+// TrackAll tracks an event for a customer in each named workspace, or in
+// every workspace with a Track client if names is empty.
+func (r *Registry) TrackAll(ctx context.Context, names []string, customerID, eventName string, data map[string]any, opts ...TrackOption) WorkspaceResults {
+	return r.FanOut(ctx, names, func(ctx context.Context, c *CustomerIO) error {
+		return c.TrackCtx(ctx, customerID, eventName, data, opts...)
+	})
+}
@@ -0,0 +1,172 @@
+package customerio_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+// siteServer records the site ID of every Track request and rejects
+// requests from sites listed in fail.
+func siteServer(t *testing.T, fail ...string) (string, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var sites []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		site, _, _ := req.BasicAuth()
+		mu.Lock()
+		sites = append(sites, site)
+		mu.Unlock()
+		if slices.Contains(fail, site) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		sorted := slices.Clone(sites)
+		slices.Sort(sorted)
+		return sorted
+	}
+}
+
+func testRegistry(t *testing.T, url string) *customerio.Registry {
+	t.Helper()
+	r, err := customerio.NewRegistry(
+		customerio.Workspace{Name: "a", Config: customerio.Config{SiteID: "site-a", TrackAPIKey: "key", URL: url}},
+		customerio.Workspace{Name: "b", Config: customerio.Config{SiteID: "site-b", TrackAPIKey: "key", AppAPIKey: "app", URL: url}},
+		customerio.Workspace{Name: "app-only", Config: customerio.Config{AppAPIKey: "app", URL: url}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRegistryLookup(t *testing.T) {
+	url, sites := siteServer(t)
+	r := testRegistry(t, url)
+
+	if got, want := r.Names(), []string{"a", "app-only", "b"}; !slices.Equal(got, want) {
+		t.Errorf("got names %v, want %v", got, want)
+	}
+
+	track, err := r.TrackClient("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := track.Identify("1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := sites(); !slices.Equal(got, []string{"site-a"}) {
+		t.Errorf("unexpected sites %v", got)
+	}
+
+	if _, err := r.APIClient("b"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := r.APIClient("a"); err == nil {
+		t.Error("expected error for workspace without App API key")
+	}
+	if _, err := r.TrackClient("app-only"); err == nil {
+		t.Error("expected error for workspace without Track API key")
+	}
+	if _, err := r.TrackClient("missing"); !errors.Is(err, customerio.ErrUnknownWorkspace) {
+		t.Errorf("expected ErrUnknownWorkspace, got %v", err)
+	}
+
+	r.Remove("a")
+	if _, err := r.TrackClient("a"); !errors.Is(err, customerio.ErrUnknownWorkspace) {
+		t.Errorf("expected ErrUnknownWorkspace after Remove, got %v", err)
+	}
+}
+
+func TestRegistryRoutesByContext(t *testing.T) {
+	url, sites := siteServer(t)
+	r := testRegistry(t, url)
+
+	if _, err := r.TrackClientFor(context.Background()); !errors.Is(err, customerio.ErrNoWorkspace) {
+		t.Errorf("expected ErrNoWorkspace, got %v", err)
+	}
+
+	ctx := customerio.ContextWithWorkspace(context.Background(), "b")
+	if name, _ := customerio.WorkspaceFromContext(ctx); name != "b" {
+		t.Errorf("got workspace %q", name)
+	}
+	track, err := r.TrackClientFor(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := track.TrackCtx(ctx, "1", "purchase", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := sites(); !slices.Equal(got, []string{"site-b"}) {
+		t.Errorf("unexpected sites %v", got)
+	}
+	if _, err := r.APIClientFor(ctx); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRegistryFanOut(t *testing.T) {
+	url, sites := siteServer(t, "site-b")
+	r := testRegistry(t, url)
+
+	results := r.IdentifyAll(context.Background(), []string{"a", "b", "missing"}, "1", map[string]any{"plan": "pro"})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].Workspace != "a" || results[0].Err != nil {
+		t.Errorf("unexpected result %+v", results[0])
+	}
+	var cerr *customerio.CustomerIOError
+	if results[1].Workspace != "b" || !errors.As(results[1].Err, &cerr) {
+		t.Errorf("unexpected result %+v", results[1])
+	}
+	if !errors.Is(results[2].Err, customerio.ErrUnknownWorkspace) {
+		t.Errorf("unexpected result %+v", results[2])
+	}
+	if err := results.Err(); err == nil || !errors.Is(err, customerio.ErrUnknownWorkspace) {
+		t.Errorf("unexpected joined error %v", err)
+	}
+	if got := sites(); !slices.Equal(got, []string{"site-a", "site-b"}) {
+		t.Errorf("unexpected sites %v", got)
+	}
+
+	// With no names, only workspaces with Track credentials are included.
+	results = r.TrackAll(context.Background(), nil, "1", "purchase", nil)
+	if len(results) != 2 || results[0].Workspace != "a" || results[0].Err != nil || results[1].Workspace != "b" {
+		t.Errorf("unexpected results %+v", results)
+	}
+	results = r.IdentifyAll(context.Background(), nil, "1", nil)
+	for _, res := range results {
+		if res.Workspace == "app-only" {
+			t.Errorf("app-only workspace included by default: %+v", res)
+		}
+	}
+}
+
+func TestNewRegistryErrors(t *testing.T) {
+	_, err := customerio.NewRegistry(
+		customerio.Workspace{Name: "", Config: customerio.Config{AppAPIKey: "app"}},
+		customerio.Workspace{Name: "empty"},
+		customerio.Workspace{Name: "half", Config: customerio.Config{SiteID: "site"}},
+	)
+	var perr customerio.ParamError
+	if !errors.As(err, &perr) || perr.Param != "Name" {
+		t.Errorf("expected Name ParamError, got %v", err)
+	}
+	for _, want := range []string{`"empty": no credentials`, `"half"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not mention %s", err, want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package customerio
+
+import (
+	"context"
+	"reflect"
+	"slices"
+)
+
+// ReportingWebhook configures where Customer.io posts message metric events.
+// Use the webhooks package to receive them.
+type ReportingWebhook struct {
+	ID       int    `json:"id,omitempty"`
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"`
+	// Events lists the subscribed metrics, named <object type>_<metric>, e.g.
+	// "email_delivered" or "push_opened".
+	Events   []string `json:"events"`
+	Disabled bool     `json:"disabled"`
+	// FullResolution sends every event rather than only the first of each
+	// metric per message, e.g. every open instead of the first.
+	FullResolution bool `json:"full_resolution"`
+	// WithContent includes message content in sent events.
+	WithContent bool `json:"with_content"`
+}
+
+func (w ReportingWebhook) validate() error {
+	if w.Name == "" {
+		return ParamError{Param: "name"}
+	}
+	if w.Endpoint == "" {
+		return ParamError{Param: "endpoint"}
+	}
+	if len(w.Events) == 0 {
+		return ParamError{Param: "events"}
+	}
+	return nil
+}
+
+// CreateReportingWebhook creates a reporting webhook; webhook.ID is ignored.
+// See https://docs.customer.io/api/app/#operation/createReportingWebhook
+func (c *APIClient) CreateReportingWebhook(ctx context.Context, webhook ReportingWebhook) (*ReportingWebhook, error) {
+	if err := webhook.validate(); err != nil {
+		return nil, err
+	}
+	webhook.ID = 0
+
+	var created ReportingWebhook
+	if err := c.requestJSON(ctx, "POST", "/v1/reporting_webhooks", webhook, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// ListReportingWebhooks returns every reporting webhook in the workspace.
+// See https://docs.customer.io/api/app/#operation/listReportingWebhooks
+func (c *APIClient) ListReportingWebhooks(ctx context.Context) ([]ReportingWebhook, error) {
+	var resp struct {
+		Webhooks []ReportingWebhook `json:"reporting_webhooks"`
+	}
+	if err := c.requestJSON(ctx, "GET", "/v1/reporting_webhooks", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Webhooks, nil
+}
+
+// GetReportingWebhook returns a single reporting webhook.
+// See https://docs.customer.io/api/app/#operation/getReportingWebhook
+func (c *APIClient) GetReportingWebhook(ctx context.Context, webhookID int) (*ReportingWebhook, error) {
+	if webhookID <= 0 {
+		return nil, ParamError{Param: "webhookID"}
+	}
+
+	var webhook ReportingWebhook
+	if err := c.requestJSON(ctx, "GET", formatPath("/v1/reporting_webhooks/%d", webhookID), nil, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// UpdateReportingWebhook replaces the configuration of the reporting webhook
+// identified by webhook.ID.
+// See https://docs.customer.io/api/app/#operation/updateReportingWebhook
+func (c *APIClient) UpdateReportingWebhook(ctx context.Context, webhook ReportingWebhook) (*ReportingWebhook, error) {
+	if webhook.ID <= 0 {
+		return nil, ParamError{Param: "webhookID"}
+	}
+	if err := webhook.validate(); err != nil {
+		return nil, err
+	}
+
+	var updated ReportingWebhook
+	if err := c.requestJSON(ctx, "PUT", formatPath("/v1/reporting_webhooks/%d", webhook.ID), webhook, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteReportingWebhook deletes a reporting webhook.
+// See https://docs.customer.io/api/app/#operation/deleteReportingWebhook
+func (c *APIClient) DeleteReportingWebhook(ctx context.Context, webhookID int) error {
+	if webhookID <= 0 {
+		return ParamError{Param: "webhookID"}
+	}
+	return c.requestJSON(ctx, "DELETE", formatPath("/v1/reporting_webhooks/%d", webhookID), nil, nil)
+}
+
+// EnsureReportingWebhook makes the workspace's reporting webhook named
+// want.Name match want, creating it if no webhook has that name and updating
+// it if its configuration differs; want.ID is ignored. It returns the
+// resulting webhook and whether a change was made.
+func (c *APIClient) EnsureReportingWebhook(ctx context.Context, want ReportingWebhook) (*ReportingWebhook, bool, error) {
+	if err := want.validate(); err != nil {
+		return nil, false, err
+	}
+
+	existing, err := c.ListReportingWebhooks(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, have := range existing {
+		if have.Name != want.Name {
+			continue
+		}
+		want.ID = have.ID
+		if sameReportingWebhook(have, want) {
+			return &have, false, nil
+		}
+		updated, err := c.UpdateReportingWebhook(ctx, want)
+		if err != nil {
+			return nil, false, err
+		}
+		return updated, true, nil
+	}
+
+	created, err := c.CreateReportingWebhook(ctx, want)
+	if err != nil {
+		return nil, false, err
+	}
+	return created, true, nil
+}
+
+// sameReportingWebhook compares two webhooks, ignoring the order of events.
+func sameReportingWebhook(a, b ReportingWebhook) bool {
+	a.Events = slices.Clone(a.Events)
+	b.Events = slices.Clone(b.Events)
+	slices.Sort(a.Events)
+	slices.Sort(b.Events)
+	return reflect.DeepEqual(a, b)
+}
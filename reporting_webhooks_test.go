@@ -0,0 +1,148 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+// reportingWebhookServer is an in-memory stand-in for the reporting webhooks API.
+func reportingWebhookServer(t *testing.T, webhooks map[int]customerio.ReportingWebhook) (*customerio.APIClient, *[]string) {
+	t.Helper()
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls = append(calls, req.Method+" "+req.URL.Path)
+
+		id, _ := strconv.Atoi(strings.TrimPrefix(req.URL.Path, "/v1/reporting_webhooks/"))
+		switch {
+		case req.Method == "GET" && id == 0:
+			var list []customerio.ReportingWebhook
+			for _, wh := range webhooks {
+				list = append(list, wh)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"reporting_webhooks": list})
+		case req.Method == "GET":
+			wh, ok := webhooks[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(wh)
+		case req.Method == "POST", req.Method == "PUT":
+			var wh customerio.ReportingWebhook
+			if err := json.NewDecoder(req.Body).Decode(&wh); err != nil {
+				t.Error(err)
+			}
+			if req.Method == "POST" {
+				id = len(webhooks) + 1
+			}
+			wh.ID = id
+			webhooks[id] = wh
+			_ = json.NewEncoder(w).Encode(wh)
+		case req.Method == "DELETE":
+			delete(webhooks, id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL)), &calls
+}
+
+func TestReportingWebhookCRUD(t *testing.T) {
+	store := map[int]customerio.ReportingWebhook{}
+	api, _ := reportingWebhookServer(t, store)
+	ctx := context.Background()
+
+	created, err := api.CreateReportingWebhook(ctx, customerio.ReportingWebhook{
+		Name:     "warehouse",
+		Endpoint: "https://example.com/cio",
+		Events:   []string{"email_sent", "email_delivered"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != 1 {
+		t.Fatalf("unexpected id %d", created.ID)
+	}
+
+	created.Disabled = true
+	if _, err := api.UpdateReportingWebhook(ctx, *created); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := api.GetReportingWebhook(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, created) {
+		t.Errorf("want %#v got %#v", created, got)
+	}
+
+	list, err := api.ListReportingWebhooks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Errorf("expected 1 webhook, got %d", len(list))
+	}
+
+	if err := api.DeleteReportingWebhook(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(store) != 0 {
+		t.Errorf("expected webhook to be deleted")
+	}
+
+	_, err = api.CreateReportingWebhook(ctx, customerio.ReportingWebhook{Name: "x", Endpoint: "https://example.com"})
+	checkParamError(t, err, "events")
+	_, err = api.UpdateReportingWebhook(ctx, customerio.ReportingWebhook{Name: "x"})
+	checkParamError(t, err, "webhookID")
+	checkParamError(t, api.DeleteReportingWebhook(ctx, 0), "webhookID")
+}
+
+func TestEnsureReportingWebhook(t *testing.T) {
+	want := customerio.ReportingWebhook{
+		Name:     "warehouse",
+		Endpoint: "https://example.com/cio",
+		Events:   []string{"email_sent", "email_delivered"},
+	}
+
+	store := map[int]customerio.ReportingWebhook{}
+	api, calls := reportingWebhookServer(t, store)
+	ctx := context.Background()
+
+	if _, changed, err := api.EnsureReportingWebhook(ctx, want); err != nil || !changed {
+		t.Fatalf("expected create, got changed=%v err=%v", changed, err)
+	}
+
+	// Same config with events reordered is a no-op.
+	want.Events = []string{"email_delivered", "email_sent"}
+	*calls = nil
+	if _, changed, err := api.EnsureReportingWebhook(ctx, want); err != nil || changed {
+		t.Fatalf("expected no change, got changed=%v err=%v", changed, err)
+	}
+	if !reflect.DeepEqual(*calls, []string{"GET /v1/reporting_webhooks"}) {
+		t.Errorf("unexpected calls %v", *calls)
+	}
+
+	want.FullResolution = true
+	*calls = nil
+	got, changed, err := api.EnsureReportingWebhook(ctx, want)
+	if err != nil || !changed {
+		t.Fatalf("expected update, got changed=%v err=%v", changed, err)
+	}
+	if !reflect.DeepEqual(*calls, []string{"GET /v1/reporting_webhooks", "PUT /v1/reporting_webhooks/1"}) {
+		t.Errorf("unexpected calls %v", *calls)
+	}
+	if got.ID != 1 || !got.FullResolution || !store[1].FullResolution {
+		t.Errorf("unexpected webhook %#v", got)
+	}
+}
@@ -0,0 +1,124 @@
+package customerio
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxScheduleAhead is how far in the future Customer.io accepts a
+// transactional message's send_at.
+const MaxScheduleAhead = 90 * 24 * time.Hour
+
+// ErrInvalidSchedule is returned by the ScheduleAt and ScheduleIn methods for
+// a time in the past or more than MaxScheduleAhead in the future.
+var ErrInvalidSchedule = errors.New("invalid send time")
+
+// sendAtSkew is how far in the past a send_at may be when a request is
+// validated, allowing for a message scheduled moments ahead that takes a
+// little while to send and for small clock differences.
+const sendAtSkew = 5 * time.Second
+
+// checkSendAt reports why a send_at Unix timestamp is outside the scheduling
+// window, or "" if it is not. Times up to skew before now are accepted.
+func checkSendAt(sendAt int64, now time.Time, skew time.Duration) string {
+	t := time.Unix(sendAt, 0)
+	switch {
+	case t.Before(now.Add(-skew)):
+		return fmt.Sprintf("%s is in the past", t.UTC().Format(time.RFC3339))
+	case t.After(now.Add(MaxScheduleAhead)):
+		if ms := time.UnixMilli(sendAt); ms.After(now.AddDate(-1, 0, 0)) && ms.Before(now.Add(MaxScheduleAhead)) {
+			return fmt.Sprintf("%d looks like milliseconds; send_at is in seconds", sendAt)
+		}
+		return fmt.Sprintf("%s is more than %d days ahead", t.UTC().Format(time.RFC3339), MaxScheduleAhead/(24*time.Hour))
+	}
+	return ""
+}
+
+// requestSendAt returns the SendAt field of a transactional request.
+func requestSendAt(req any) *int64 {
+	switch r := req.(type) {
+	case *SendEmailRequest:
+		return r.SendAt
+	case *SendPushRequest:
+		return r.SendAt
+	case *SendSMSRequest:
+		return r.SendAt
+	case *SendInAppRequest:
+		return r.SendAt
+	case *SendInboxMessageRequest:
+		return r.SendAt
+	}
+	return nil
+}
+
+// ScheduleAt sets the email to be sent at t, which must be in the future and
+// no more than MaxScheduleAhead away.
+func (r *SendEmailRequest) ScheduleAt(t time.Time) (err error) {
+	r.SendAt, err = scheduleAtOr(r.SendAt, t)
+	return err
+}
+
+// ScheduleIn sets the email to be sent after d.
+func (r *SendEmailRequest) ScheduleIn(d time.Duration) error {
+	return r.ScheduleAt(time.Now().Add(d))
+}
+
+// ScheduleAt sets the push to be sent at t, which must be in the future and
+// no more than MaxScheduleAhead away.
+func (r *SendPushRequest) ScheduleAt(t time.Time) (err error) {
+	r.SendAt, err = scheduleAtOr(r.SendAt, t)
+	return err
+}
+
+// ScheduleIn sets the push to be sent after d.
+func (r *SendPushRequest) ScheduleIn(d time.Duration) error {
+	return r.ScheduleAt(time.Now().Add(d))
+}
+
+// ScheduleAt sets the SMS to be sent at t, which must be in the future and
+// no more than MaxScheduleAhead away.
+func (r *SendSMSRequest) ScheduleAt(t time.Time) (err error) {
+	r.SendAt, err = scheduleAtOr(r.SendAt, t)
+	return err
+}
+
+// ScheduleIn sets the SMS to be sent after d.
+func (r *SendSMSRequest) ScheduleIn(d time.Duration) error {
+	return r.ScheduleAt(time.Now().Add(d))
+}
+
+// ScheduleAt sets the in-app message to be sent at t, which must be in the
+// future and no more than MaxScheduleAhead away.
+func (r *SendInAppRequest) ScheduleAt(t time.Time) (err error) {
+	r.SendAt, err = scheduleAtOr(r.SendAt, t)
+	return err
+}
+
+// ScheduleIn sets the in-app message to be sent after d.
+func (r *SendInAppRequest) ScheduleIn(d time.Duration) error {
+	return r.ScheduleAt(time.Now().Add(d))
+}
+
+// ScheduleAt sets the inbox message to be sent at t, which must be in the
+// future and no more than MaxScheduleAhead away.
+func (r *SendInboxMessageRequest) ScheduleAt(t time.Time) (err error) {
+	r.SendAt, err = scheduleAtOr(r.SendAt, t)
+	return err
+}
+
+// ScheduleIn sets the inbox message to be sent after d.
+func (r *SendInboxMessageRequest) ScheduleIn(d time.Duration) error {
+	return r.ScheduleAt(time.Now().Add(d))
+}
+
+// scheduleAtOr returns t as a send_at timestamp, rounded up to a whole
+// second so it is never earlier than t, or current unchanged with an error if
+// t is outside the scheduling window.
+func scheduleAtOr(current *int64, t time.Time) (*int64, error) {
+	sendAt := t.Add(time.Second - 1).Unix()
+	if msg := checkSendAt(sendAt, time.Now(), 0); msg != "" {
+		return current, fmt.Errorf("%w: %s", ErrInvalidSchedule, msg)
+	}
+	return &sendAt, nil
+}
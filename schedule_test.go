@@ -0,0 +1,150 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestScheduleAt(t *testing.T) {
+	req := &customerio.SendSMSRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "1"}}
+
+	at := time.Now().Add(24 * time.Hour)
+	if err := req.ScheduleAt(at); err != nil {
+		t.Fatal(err)
+	}
+	want := at.Add(time.Second - 1).Unix()
+	if req.SendAt == nil || *req.SendAt != want {
+		t.Fatalf("expected send_at %d, got %v", want, req.SendAt)
+	}
+
+	for name, when := range map[string]time.Time{
+		"past":    time.Now().Add(-time.Minute),
+		"too far": time.Now().Add(customerio.MaxScheduleAhead + time.Hour),
+	} {
+		if err := req.ScheduleAt(when); !errors.Is(err, customerio.ErrInvalidSchedule) {
+			t.Errorf("%s: expected ErrInvalidSchedule, got %v", name, err)
+		}
+		if *req.SendAt != want {
+			t.Errorf("%s: send_at changed despite error", name)
+		}
+	}
+
+	email := &customerio.SendEmailRequest{}
+	before := time.Now()
+	if err := email.ScheduleIn(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got := time.Unix(*email.SendAt, 0); got.Before(before.Add(time.Hour)) || got.After(time.Now().Add(time.Hour+time.Second)) {
+		t.Errorf("ScheduleIn set %v", got)
+	}
+	for _, r := range []interface{ ScheduleIn(time.Duration) error }{
+		&customerio.SendPushRequest{}, &customerio.SendInAppRequest{}, &customerio.SendInboxMessageRequest{},
+	} {
+		if err := r.ScheduleIn(-time.Hour); !errors.Is(err, customerio.ErrInvalidSchedule) {
+			t.Errorf("%T: expected ErrInvalidSchedule, got %v", r, err)
+		}
+	}
+}
+
+func TestScheduleSubSecond(t *testing.T) {
+	// Sub-second times round up, so they are never in the past when they
+	// are set or when the request is validated straight after.
+	for _, d := range []time.Duration{time.Millisecond, 100 * time.Millisecond, 500 * time.Millisecond, 999 * time.Millisecond} {
+		for i := 0; i < 20; i++ {
+			req := &customerio.SendPushRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "1"}}
+			before := time.Now()
+			if err := req.ScheduleIn(d); err != nil {
+				t.Fatalf("ScheduleIn(%v): %v", d, err)
+			}
+			if got := time.Unix(*req.SendAt, 0); got.Before(before.Add(d)) {
+				t.Fatalf("ScheduleIn(%v) set %v, before %v", d, got, before.Add(d))
+			}
+			if err := req.Validate(); err != nil {
+				t.Fatalf("ScheduleIn(%v): %v", d, err)
+			}
+		}
+	}
+	at := time.Now().Add(time.Hour).Truncate(time.Second).Add(time.Nanosecond)
+	req := &customerio.SendPushRequest{}
+	if err := req.ScheduleAt(at); err != nil {
+		t.Fatal(err)
+	}
+	if want := at.Truncate(time.Second).Add(time.Second).Unix(); *req.SendAt != want {
+		t.Errorf("expected send_at %d, got %d", want, *req.SendAt)
+	}
+}
+
+func TestValidateSendAtSkew(t *testing.T) {
+	recent := time.Now().Add(-2 * time.Second).Unix()
+	req := &customerio.SendPushRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "1"}, SendAt: &recent}
+	if err := req.Validate(); err != nil {
+		t.Errorf("send_at a moment ago should be accepted: %v", err)
+	}
+	past := time.Now().Add(-time.Minute).Unix()
+	req.SendAt = &past
+	var ferr customerio.FieldError
+	if err := req.Validate(); !errors.As(err, &ferr) || ferr.Field != "send_at" {
+		t.Errorf("expected a send_at error, got %v", err)
+	}
+}
+
+func TestValidateSendAtWindow(t *testing.T) {
+	cases := map[string]struct {
+		sendAt int64
+		msg    string
+	}{
+		"milliseconds": {time.Now().Add(time.Hour).UnixMilli(), "looks like milliseconds"},
+		"too far":      {time.Now().Add(customerio.MaxScheduleAhead + time.Hour).Unix(), "more than 90 days ahead"},
+	}
+	for name, tc := range cases {
+		req := &customerio.SendPushRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "1"}, SendAt: &tc.sendAt}
+		var ferr customerio.FieldError
+		if err := req.Validate(); !errors.As(err, &ferr) || ferr.Field != "send_at" || !strings.Contains(ferr.Message, tc.msg) {
+			t.Errorf("%s: expected send_at error containing %q, got %v", name, tc.msg, err)
+		}
+	}
+}
+
+func TestSendScheduledAt(t *testing.T) {
+	req := &customerio.SendEmailRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "1"}}
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := req.ScheduleAt(at); err != nil {
+		t.Fatal(err)
+	}
+
+	api, srv := transactionalServer(t, func(request []byte) {
+		var body map[string]any
+		if err := json.Unmarshal(request, &body); err != nil {
+			t.Error(err)
+		}
+		if req.SendAt != nil && body["send_at"] != float64(at.Unix()) {
+			t.Errorf("expected send_at %d, got %v", at.Unix(), body["send_at"])
+		}
+	})
+	defer srv.Close()
+
+	resp, err := api.SendEmail(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.ScheduledAt.Equal(at) {
+		t.Errorf("expected ScheduledAt %v, got %v", at, resp.ScheduledAt)
+	}
+	if !resp.QueuedAt.Equal(time.Unix(int64(testQueuedAt), 0)) {
+		t.Errorf("unexpected QueuedAt %v", resp.QueuedAt)
+	}
+
+	req.SendAt = nil
+	if resp, err = api.SendEmail(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.ScheduledAt.IsZero() {
+		t.Errorf("expected zero ScheduledAt for an immediate send, got %v", resp.ScheduledAt)
+	}
+}
@@ -6,12 +6,16 @@ import (
 )
 
 // AddPeopleToSegment adds customers to a manual segment by segment ID.
+// More than MaxSegmentMembershipIDs ids are sent as several sequential
+// requests; if any of them fail a *PartialFailureError is returned.
 // See https://docs.customer.io/api/track/#operation/add_customers
 func (c *CustomerIO) AddPeopleToSegment(ctx context.Context, segmentID int, ids []string, opts ...SegmentOption) error {
 	return c.segmentMembership(ctx, "add_customers", segmentID, ids, opts...)
 }
 
 // RemovePeopleFromSegment removes customers from a manual segment by segment ID.
+// More than MaxSegmentMembershipIDs ids are sent as several sequential
+// requests; if any of them fail a *PartialFailureError is returned.
 // See https://docs.customer.io/api/track/#operation/remove_customers
 func (c *CustomerIO) RemovePeopleFromSegment(ctx context.Context, segmentID int, ids []string, opts ...SegmentOption) error {
 	return c.segmentMembership(ctx, "remove_customers", segmentID, ids, opts...)
@@ -37,7 +41,29 @@ func (c *CustomerIO) segmentMembership(ctx context.Context, action string, segme
 		u += "?" + encoded
 	}
 
-	return c.request(ctx, "POST", u, map[string]interface{}{
-		"ids": ids,
-	})
+	if len(ids) <= MaxSegmentMembershipIDs {
+		return c.request(ctx, "POST", u, map[string]interface{}{
+			"ids": ids,
+		})
+	}
+
+	chunks := chunk(ids, MaxSegmentMembershipIDs)
+	var failed []ChunkError
+	for i, part := range chunks {
+		if err := ctx.Err(); err != nil {
+			for j, rest := range chunks[i:] {
+				failed = append(failed, ChunkError{Index: i + j, IDs: rest, Err: err})
+			}
+			break
+		}
+		if err := c.request(ctx, "POST", u, map[string]interface{}{
+			"ids": part,
+		}); err != nil {
+			failed = append(failed, ChunkError{Index: i, IDs: part, Err: err})
+		}
+	}
+	if len(failed) > 0 {
+		return &PartialFailureError{Chunks: len(chunks), Failed: failed}
+	}
+	return nil
 }
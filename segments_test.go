@@ -2,6 +2,12 @@ package customerio_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/customerio/go-customerio/v3"
@@ -66,3 +72,78 @@ func TestRemovePeopleFromSegment(t *testing.T) {
 			}
 		})
 }
+
+func TestAddPeopleToSegmentChunksLargeInput(t *testing.T) {
+	var sizes []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			IDs []string `json:"ids"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		sizes = append(sizes, len(body.IDs))
+		if body.IDs[0] == "1000" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := customerio.NewTrackClient("siteid", "apikey", customerio.WithURL(srv.URL))
+
+	ids := make([]string, 2*customerio.MaxSegmentMembershipIDs+1)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+
+	err := client.AddPeopleToSegment(context.Background(), 7, ids)
+
+	want := []int{customerio.MaxSegmentMembershipIDs, customerio.MaxSegmentMembershipIDs, 1}
+	if !reflect.DeepEqual(sizes, want) {
+		t.Errorf("chunk sizes: want %v got %v", want, sizes)
+	}
+
+	var pe *customerio.PartialFailureError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected PartialFailureError, got %#v", err)
+	}
+	if pe.Chunks != 3 || len(pe.Failed) != 1 || pe.Failed[0].Index != 1 {
+		t.Fatalf("unexpected failure report: %v", pe)
+	}
+	if got := pe.FailedIDs(); !reflect.DeepEqual(got, ids[1000:2000]) {
+		t.Errorf("unexpected failed ids: %d ids starting at %v", len(got), got[0])
+	}
+	var cerr *customerio.CustomerIOError
+	if !errors.As(err, &cerr) || cerr.StatusCode() != http.StatusBadRequest {
+		t.Errorf("expected wrapped CustomerIOError, got %#v", err)
+	}
+}
+
+func TestSegmentMembershipStopsWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls int
+	client := customerio.NewTrackClient("siteid", "apikey", customerio.WithHTTPClient(httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		cancel()
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})))
+	ids := make([]string, 2*customerio.MaxSegmentMembershipIDs+1)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+
+	err := client.RemovePeopleFromSegment(ctx, 7, ids)
+	if calls != 1 {
+		t.Fatalf("expected 1 request before cancellation, got %d", calls)
+	}
+	var pe *customerio.PartialFailureError
+	if !errors.As(err, &pe) || len(pe.Failed) != 2 || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the remaining chunks to fail with context.Canceled, got %v", err)
+	}
+	if got := pe.FailedIDs(); !reflect.DeepEqual(got, ids[customerio.MaxSegmentMembershipIDs:]) {
+		t.Errorf("unexpected failed ids: %d ids", len(got))
+	}
+}
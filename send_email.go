@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
 )
 
@@ -32,16 +33,21 @@ type SendEmailRequest struct {
 	DisableCSSPreprocessing *bool             `json:"disable_css_preprocessing,omitempty"`
 	SendAt                  *int64            `json:"send_at,omitempty"`
 	Language                *string           `json:"language,omitempty"`
+
+	// files holds the attachments added by AttachFile and AttachFS.
+	files []attachmentFile
 }
 
 var ErrAttachmentExists = errors.New("attachment with this name already exists")
 
+// Attach reads value and adds it as an attachment called name. The encoded
+// contents are held in memory; use AttachFile or AttachFS for large files.
 func (e *SendEmailRequest) Attach(name string, value io.Reader) error {
 	if e.Attachments == nil {
 		e.Attachments = map[string]string{}
 	}
-	if _, ok := e.Attachments[name]; ok {
-		return ErrAttachmentExists
+	if err := e.checkAttachment(name, 0); err != nil {
+		return err
 	}
 
 	var buf bytes.Buffer
@@ -54,9 +60,52 @@ func (e *SendEmailRequest) Attach(name string, value io.Reader) error {
 	}
 
 	e.Attachments[name] = buf.String()
+	if total := e.attachmentsSize(); total > MaxAttachmentsSize {
+		delete(e.Attachments, name)
+		return fmt.Errorf("%w: adding %s makes %d bytes, limit is %d", ErrAttachmentsTooLarge, name, total, MaxAttachmentsSize)
+	}
+	return nil
+}
+
+// SetIdentifier validates id and sets it as the only identifier of the
+// person receiving the email.
+func (r *SendEmailRequest) SetIdentifier(id Identifier) error {
+	ids, err := identifierMap(id)
+	if err != nil {
+		return err
+	}
+	r.Identifiers = ids
 	return nil
 }
 
+// Validate reports every problem with the request that Customer.io would
+// reject, as a *ValidationError. SendEmail calls it before sending.
+func (r *SendEmailRequest) Validate() error {
+	var f fieldErrors
+	f.transactional(r.Identifiers, r.SendAt, r.Language)
+	if r.TransactionalMessageID == "" {
+		// Inline emails must supply their own content.
+		if r.Body == "" {
+			f.add("body", "required without transactional_message_id")
+		}
+		if r.Subject == "" {
+			f.add("subject", "required without transactional_message_id")
+		}
+		if r.From == "" {
+			f.add("from", "required without transactional_message_id")
+		}
+	}
+	for name := range r.Attachments {
+		if err := checkAttachmentName(name); err != nil {
+			f.add("attachments", "%v", err)
+		}
+	}
+	if total := r.attachmentsSize(); total > MaxAttachmentsSize {
+		f.add("attachments", "%d bytes exceeds the %d byte limit", total, MaxAttachmentsSize)
+	}
+	return f.err()
+}
+
 type SendEmailResponse struct {
 	TransactionalResponse
 }
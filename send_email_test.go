@@ -1,12 +1,20 @@
 package customerio_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/customerio/go-customerio/v3"
@@ -88,3 +96,155 @@ func TestSendEmailError(t *testing.T) {
 		t.Errorf("Expected TransactionalError, got: %#v", err)
 	}
 }
+
+func TestSendEmailStreamsFileAttachments(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "receipt.pdf"), []byte("%PDF receipt"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{"docs/terms.txt": {Data: []byte("terms")}}
+
+	req := &customerio.SendEmailRequest{
+		TransactionalMessageID: "1",
+		Identifiers:            map[string]string{"id": "customer_1"},
+	}
+	if err := req.Attach("note.txt", strings.NewReader("note")); err != nil {
+		t.Fatal(err)
+	}
+	if err := req.AttachFile(filepath.Join(dir, "receipt.pdf")); err != nil {
+		t.Fatal(err)
+	}
+	if err := req.AttachFS(fsys, "docs/terms.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	var body customerio.SendEmailRequest
+	var contentLength int64
+	var raw []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Redirecting makes the client send the body a second time.
+		if r.URL.Path != "/redirected" {
+			_, _ = io.Copy(io.Discard, r.Body)
+			http.Redirect(w, r, "/redirected", http.StatusTemporaryRedirect)
+			return
+		}
+		contentLength = r.ContentLength
+		var err error
+		if raw, err = io.ReadAll(r.Body); err != nil {
+			t.Error(err)
+		}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte(`{"delivery_id": "d", "queued_at": 1}`))
+	}))
+	defer srv.Close()
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	if _, err := api.SendEmail(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if contentLength != int64(len(raw)) {
+		t.Errorf("got content length %d for a %d byte body", contentLength, len(raw))
+	}
+	want := map[string]string{
+		"note.txt":    base64.StdEncoding.EncodeToString([]byte("note")),
+		"receipt.pdf": base64.StdEncoding.EncodeToString([]byte("%PDF receipt")),
+		"terms.txt":   base64.StdEncoding.EncodeToString([]byte("terms")),
+	}
+	if !reflect.DeepEqual(body.Attachments, want) {
+		t.Errorf("got attachments %v, want %v", body.Attachments, want)
+	}
+	if body.TransactionalMessageID != "1" || body.Identifiers["id"] != "customer_1" {
+		t.Errorf("unexpected request %#v", body)
+	}
+}
+
+func TestSendEmailAttachedFileChanged(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"delivery_id": "d", "queued_at": 1}`))
+	}))
+	defer srv.Close()
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	for name, data := range map[string]string{"grown": "receipt and more", "shrunk": "rec"} {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "receipt.txt")
+			if err := os.WriteFile(file, []byte("receipt"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			req := &customerio.SendEmailRequest{
+				TransactionalMessageID: "1",
+				Identifiers:            map[string]string{"id": "customer_1"},
+			}
+			if err := req.AttachFile(file); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := api.SendEmail(context.Background(), req); !errors.Is(err, customerio.ErrAttachmentChanged) {
+				t.Errorf("expected ErrAttachmentChanged, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSendEmailAttachmentChecks(t *testing.T) {
+	fsys := fstest.MapFS{
+		"big.bin":   {Data: make([]byte, customerio.MaxAttachmentsSize-10)},
+		"small.bin": {Data: make([]byte, 11)},
+		"setup.EXE": {Data: []byte("MZ")},
+		"dir/a.txt": {Data: []byte("a")},
+	}
+	var req customerio.SendEmailRequest
+
+	if err := req.AttachFS(fsys, "setup.EXE"); !errors.Is(err, customerio.ErrAttachmentBlocked) {
+		t.Errorf("expected ErrAttachmentBlocked, got %v", err)
+	}
+	if err := req.Attach("run.js", strings.NewReader("")); !errors.Is(err, customerio.ErrAttachmentBlocked) {
+		t.Errorf("expected ErrAttachmentBlocked, got %v", err)
+	}
+	if err := req.AttachFS(fsys, "dir"); err == nil {
+		t.Error("expected error for directory")
+	}
+	if err := req.AttachFS(fsys, "big.bin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := req.AttachFS(fsys, "big.bin"); !errors.Is(err, customerio.ErrAttachmentExists) {
+		t.Errorf("expected ErrAttachmentExists, got %v", err)
+	}
+	if err := req.AttachFS(fsys, "small.bin"); !errors.Is(err, customerio.ErrAttachmentsTooLarge) {
+		t.Errorf("expected ErrAttachmentsTooLarge, got %v", err)
+	}
+	if err := req.Attach("more.bin", bytes.NewReader(make([]byte, 11))); !errors.Is(err, customerio.ErrAttachmentsTooLarge) {
+		t.Errorf("expected ErrAttachmentsTooLarge, got %v", err)
+	}
+	if _, ok := req.Attachments["more.bin"]; ok {
+		t.Error("oversized attachment was kept")
+	}
+	if err := req.Attach("ok.txt", strings.NewReader("0123456789")); err != nil {
+		t.Errorf("unexpected error at the limit: %v", err)
+	}
+}
+
+func TestSendEmailValidateAttachments(t *testing.T) {
+	req := &customerio.SendEmailRequest{
+		TransactionalMessageID: "1",
+		Identifiers:            map[string]string{"id": "1"},
+		Attachments: map[string]string{
+			"virus.exe": "TVo=",
+			"big.bin":   base64.StdEncoding.EncodeToString(make([]byte, customerio.MaxAttachmentsSize)),
+		},
+	}
+	var verr *customerio.ValidationError
+	if err := req.Validate(); !errors.As(err, &verr) || len(verr.Errors) != 2 {
+		t.Fatalf("expected two attachment errors, got %v", err)
+	}
+	for _, f := range verr.Errors {
+		if f.Field != "attachments" {
+			t.Errorf("unexpected field %q", f.Field)
+		}
+	}
+}
@@ -14,6 +14,28 @@ type SendInAppRequest struct {
 	Language                *string           `json:"language,omitempty"`
 }
 
+// SetIdentifier validates id and sets it as the only identifier of the
+// person receiving the in-app message.
+func (r *SendInAppRequest) SetIdentifier(id Identifier) error {
+	ids, err := identifierMap(id)
+	if err != nil {
+		return err
+	}
+	r.Identifiers = ids
+	return nil
+}
+
+// Validate reports every problem with the request that Customer.io would
+// reject, as a *ValidationError. SendInApp calls it before sending.
+func (r *SendInAppRequest) Validate() error {
+	var f fieldErrors
+	f.transactional(r.Identifiers, r.SendAt, r.Language)
+	if r.TransactionalMessageID == "" {
+		f.add("transactional_message_id", "missing")
+	}
+	return f.err()
+}
+
 type SendInAppResponse struct {
 	TransactionalResponse
 }
@@ -26,6 +26,41 @@ type SendPushRequest struct {
 	Sound         string          `json:"sound,omitempty"`
 }
 
+// SetIdentifier validates id and sets it as the only identifier of the
+// person receiving the push.
+func (r *SendPushRequest) SetIdentifier(id Identifier) error {
+	ids, err := identifierMap(id)
+	if err != nil {
+		return err
+	}
+	r.Identifiers = ids
+	return nil
+}
+
+// Validate reports every problem with the request that Customer.io would
+// reject, as a *ValidationError. SendPush calls it before sending.
+func (r *SendPushRequest) Validate() error {
+	var f fieldErrors
+	f.transactional(r.Identifiers, r.SendAt, r.Language)
+	if r.TransactionalMessageID == "" && r.Message == "" {
+		f.add("message", "required without transactional_message_id")
+	}
+	if len(r.CustomPayload) > 0 {
+		f.payloadSizes(r.CustomPayload)
+	}
+	if r.Device != nil {
+		switch {
+		case r.Device.Token == "":
+			f.add("custom_device.token", "missing")
+		case r.Device.Platform != "":
+			if err := Platform(r.Device.Platform).ValidateToken(r.Device.Token); err != nil {
+				f.add("custom_device.token", "%v", err)
+			}
+		}
+	}
+	return f.err()
+}
+
 type SendPushResponse struct {
 	TransactionalResponse
 }
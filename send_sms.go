@@ -18,6 +18,28 @@ type SendSMSRequest struct {
 	To   string `json:"to,omitempty"`
 }
 
+// SetIdentifier validates id and sets it as the only identifier of the
+// person receiving the SMS.
+func (r *SendSMSRequest) SetIdentifier(id Identifier) error {
+	ids, err := identifierMap(id)
+	if err != nil {
+		return err
+	}
+	r.Identifiers = ids
+	return nil
+}
+
+// Validate reports every problem with the request that Customer.io would
+// reject, as a *ValidationError. SendSMS calls it before sending.
+func (r *SendSMSRequest) Validate() error {
+	var f fieldErrors
+	f.transactional(r.Identifiers, r.SendAt, r.Language)
+	if r.TransactionalMessageID == "" {
+		f.add("transactional_message_id", "missing")
+	}
+	return f.err()
+}
+
 type SendSMSResponse struct {
 	TransactionalResponse
 }
@@ -0,0 +1,329 @@
+package customerio
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShadowResult records the outcome of one operation sent to both the primary
+// and the shadow client of a ShadowClient.
+type ShadowResult struct {
+	// Operation names the client method, such as "IdentifyCtx".
+	Operation string
+	// Key is the customer or anonymous ID the operation was routed by.
+	Key string
+
+	PrimaryErr     error
+	ShadowErr      error
+	PrimaryLatency time.Duration
+	ShadowLatency  time.Duration
+}
+
+// Mismatch reports whether exactly one of the two clients failed.
+func (r ShadowResult) Mismatch() bool {
+	return (r.PrimaryErr == nil) != (r.ShadowErr == nil)
+}
+
+// ShadowStats summarizes the shadowed operations of a ShadowClient.
+type ShadowStats struct {
+	// Shadowed counts operations sent to the shadow client.
+	Shadowed int64
+	// Dropped counts operations that were not sent to the shadow client
+	// because the limit on in-flight shadow requests was reached.
+	Dropped int64
+	// ShadowFailures counts shadowed operations that failed in the shadow.
+	ShadowFailures int64
+	// Mismatches counts shadowed operations where exactly one client failed.
+	Mismatches int64
+	// LatencyDelta is the total shadow latency minus the total primary
+	// latency across shadowed operations.
+	LatencyDelta time.Duration
+}
+
+// DefaultMaxShadowsInFlight is the number of concurrent shadow requests a
+// ShadowClient allows unless configured with WithMaxShadowsInFlight.
+const DefaultMaxShadowsInFlight = 100
+
+// ShadowOption configures a ShadowClient.
+type ShadowOption func(*ShadowClient)
+
+// WithShadowPercent sets the percentage of customers, from 0 to 100, whose
+// operations are also sent to the shadow client. Defaults to 100.
+func WithShadowPercent(percent float64) ShadowOption {
+	return func(s *ShadowClient) {
+		s.SetShadowPercent(percent)
+	}
+}
+
+// WithMaxShadowsInFlight limits the number of shadow requests running at
+// once. Operations beyond the limit are sent to the primary only and counted
+// in ShadowStats.Dropped, so a slow shadow never holds up the primary.
+// Values below 1 are treated as 1. Defaults to DefaultMaxShadowsInFlight.
+func WithMaxShadowsInFlight(n int) ShadowOption {
+	return func(s *ShadowClient) {
+		s.inFlight = make(chan struct{}, max(n, 1))
+	}
+}
+
+// WithShadowRecorder sets a function that is called with the result of
+// every shadowed operation. It is called from a background goroutine.
+func WithShadowRecorder(fn func(ShadowResult)) ShadowOption {
+	return func(s *ShadowClient) {
+		s.record = fn
+	}
+}
+
+// ShadowClient sends each Track operation to a primary client and, for a
+// configurable share of customers, to a shadow client as well, such as a new
+// workspace during a migration. Callers only see the primary's result; the
+// shadow request runs in the background and its outcome is recorded.
+//
+// Customers are selected by hashing their ID, so the same customers stay
+// shadowed as the percentage is raised.
+type ShadowClient struct {
+	primary *CustomerIO
+	shadow  *CustomerIO
+	record  func(ShadowResult)
+
+	// basisPoints is the shadowed share of the hash space, out of 10000.
+	basisPoints atomic.Int64
+	// inFlight holds a token for every running shadow request.
+	inFlight chan struct{}
+
+	wg sync.WaitGroup
+
+	mu    sync.Mutex
+	stats ShadowStats
+}
+
+// NewShadowClient returns a ShadowClient writing to primary and shadow.
+func NewShadowClient(primary, shadow *CustomerIO, opts ...ShadowOption) *ShadowClient {
+	s := &ShadowClient{primary: primary, shadow: shadow, inFlight: make(chan struct{}, DefaultMaxShadowsInFlight)}
+	s.basisPoints.Store(10000)
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	return s
+}
+
+// SetShadowPercent changes the percentage of customers that are shadowed.
+// Values outside 0 to 100 are clamped.
+func (s *ShadowClient) SetShadowPercent(percent float64) {
+	s.basisPoints.Store(int64(min(max(percent, 0), 100) * 100))
+}
+
+// Stats returns a summary of the shadowed operations so far.
+func (s *ShadowClient) Stats() ShadowStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Wait blocks until every in-flight shadow request has finished and been
+// recorded.
+func (s *ShadowClient) Wait() {
+	s.wg.Wait()
+}
+
+func (s *ShadowClient) shadowed(key string) bool {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int64(h.Sum32()%10000) < s.basisPoints.Load()
+}
+
+// do runs fn against the primary and, if key is shadowed, the shadow client.
+func (s *ShadowClient) do(ctx context.Context, op, key string, fn func(context.Context, *CustomerIO) error) error {
+	if !s.shadowed(key) {
+		return fn(ctx, s.primary)
+	}
+	return s.run(ctx, op, key, fn, fn)
+}
+
+// run calls primaryFn on the primary client and shadowFn concurrently on the
+// shadow client, returning the primary's error once it completes. The shadow
+// call is dropped if too many are already in flight.
+func (s *ShadowClient) run(ctx context.Context, op, key string, primaryFn, shadowFn func(context.Context, *CustomerIO) error) error {
+	select {
+	case s.inFlight <- struct{}{}:
+	default:
+		s.mu.Lock()
+		s.stats.Dropped++
+		s.mu.Unlock()
+		return primaryFn(ctx, s.primary)
+	}
+
+	primaryDone := make(chan ShadowResult, 1)
+	s.wg.Add(1)
+	go func() {
+		defer func() {
+			<-s.inFlight
+			s.wg.Done()
+		}()
+		start := time.Now()
+		err := shadowFn(context.WithoutCancel(ctx), s.shadow)
+		shadowLatency := time.Since(start)
+
+		res := <-primaryDone
+		res.ShadowErr, res.ShadowLatency = err, shadowLatency
+		s.observe(res)
+	}()
+
+	start := time.Now()
+	var err error
+	defer func() {
+		// Always hand the shadow goroutine a result, even if primaryFn
+		// panicked, so it is recorded and Wait returns.
+		r := recover()
+		if r != nil {
+			err = fmt.Errorf("customerio: primary panicked: %v", r)
+		}
+		primaryDone <- ShadowResult{Operation: op, Key: key, PrimaryErr: err, PrimaryLatency: time.Since(start)}
+		if r != nil {
+			panic(r)
+		}
+	}()
+	err = primaryFn(ctx, s.primary)
+	return err
+}
+
+func (s *ShadowClient) observe(res ShadowResult) {
+	s.mu.Lock()
+	s.stats.Shadowed++
+	if res.ShadowErr != nil {
+		s.stats.ShadowFailures++
+	}
+	if res.Mismatch() {
+		s.stats.Mismatches++
+	}
+	s.stats.LatencyDelta += res.ShadowLatency - res.PrimaryLatency
+	s.mu.Unlock()
+
+	if s.record != nil {
+		s.record(res)
+	}
+}
+
+// IdentifyCtx identifies a customer and sets their attributes
+func (s *ShadowClient) IdentifyCtx(ctx context.Context, customerID string, attributes map[string]any) error {
+	return s.do(ctx, "IdentifyCtx", customerID, func(ctx context.Context, c *CustomerIO) error {
+		return c.IdentifyCtx(ctx, customerID, attributes)
+	})
+}
+
+// Identify identifies a customer and sets their attributes
+func (s *ShadowClient) Identify(customerID string, attributes map[string]any) error {
+	return s.IdentifyCtx(context.Background(), customerID, attributes)
+}
+
+// TrackCtx sends a single event to Customer.io for the supplied CustomerID
+func (s *ShadowClient) TrackCtx(ctx context.Context, customerID string, eventName string, data map[string]any, opts ...TrackOption) error {
+	return s.do(ctx, "TrackCtx", customerID, func(ctx context.Context, c *CustomerIO) error {
+		return c.TrackCtx(ctx, customerID, eventName, data, opts...)
+	})
+}
+
+// Track sends a single event to Customer.io for the supplied CustomerID
+func (s *ShadowClient) Track(customerID string, eventName string, data map[string]any, opts ...TrackOption) error {
+	return s.TrackCtx(context.Background(), customerID, eventName, data, opts...)
+}
+
+// TrackAnonymousCtx sends a single event to Customer.io for the anonymous user
+func (s *ShadowClient) TrackAnonymousCtx(ctx context.Context, anonymousID, eventName string, data map[string]any, opts ...TrackOption) error {
+	return s.do(ctx, "TrackAnonymousCtx", anonymousID, func(ctx context.Context, c *CustomerIO) error {
+		return c.TrackAnonymousCtx(ctx, anonymousID, eventName, data, opts...)
+	})
+}
+
+// TrackAnonymous sends a single event to Customer.io for the anonymous user
+func (s *ShadowClient) TrackAnonymous(anonymousID, eventName string, data map[string]any, opts ...TrackOption) error {
+	return s.TrackAnonymousCtx(context.Background(), anonymousID, eventName, data, opts...)
+}
+
+// DeleteCtx deletes a customer
+func (s *ShadowClient) DeleteCtx(ctx context.Context, customerID string) error {
+	return s.do(ctx, "DeleteCtx", customerID, func(ctx context.Context, c *CustomerIO) error {
+		return c.DeleteCtx(ctx, customerID)
+	})
+}
+
+// Delete deletes a customer
+func (s *ShadowClient) Delete(customerID string) error {
+	return s.DeleteCtx(context.Background(), customerID)
+}
+
+// AddDeviceCtx adds a device for a customer
+func (s *ShadowClient) AddDeviceCtx(ctx context.Context, customerID string, deviceID string, platform string, data map[string]any) error {
+	return s.do(ctx, "AddDeviceCtx", customerID, func(ctx context.Context, c *CustomerIO) error {
+		return c.AddDeviceCtx(ctx, customerID, deviceID, platform, data)
+	})
+}
+
+// AddDevice adds a device for a customer
+func (s *ShadowClient) AddDevice(customerID string, deviceID string, platform string, data map[string]any) error {
+	return s.AddDeviceCtx(context.Background(), customerID, deviceID, platform, data)
+}
+
+// DeleteDeviceCtx deletes a device for a customer
+func (s *ShadowClient) DeleteDeviceCtx(ctx context.Context, customerID string, deviceID string) error {
+	return s.do(ctx, "DeleteDeviceCtx", customerID, func(ctx context.Context, c *CustomerIO) error {
+		return c.DeleteDeviceCtx(ctx, customerID, deviceID)
+	})
+}
+
+// DeleteDevice deletes a device for a customer
+func (s *ShadowClient) DeleteDevice(customerID string, deviceID string) error {
+	return s.DeleteDeviceCtx(context.Background(), customerID, deviceID)
+}
+
+// MergeCustomersCtx merges two customer profiles together. The operation is
+// shadowed according to the primary identifier's value.
+func (s *ShadowClient) MergeCustomersCtx(ctx context.Context, primary Identifier, secondary Identifier) error {
+	return s.do(ctx, "MergeCustomersCtx", primary.Value, func(ctx context.Context, c *CustomerIO) error {
+		return c.MergeCustomersCtx(ctx, primary, secondary)
+	})
+}
+
+// MergeCustomers merges two customer profiles together.
+func (s *ShadowClient) MergeCustomers(primary Identifier, secondary Identifier) error {
+	return s.MergeCustomersCtx(context.Background(), primary, secondary)
+}
+
+// AddPeopleToSegment adds people to a manual segment. Only the shadowed ids
+// are sent to the shadow client.
+func (s *ShadowClient) AddPeopleToSegment(ctx context.Context, segmentID int, ids []string, opts ...SegmentOption) error {
+	return s.segment(ctx, "AddPeopleToSegment", ids, func(ctx context.Context, c *CustomerIO, ids []string) error {
+		return c.AddPeopleToSegment(ctx, segmentID, ids, opts...)
+	})
+}
+
+// RemovePeopleFromSegment removes people from a manual segment. Only the
+// shadowed ids are sent to the shadow client.
+func (s *ShadowClient) RemovePeopleFromSegment(ctx context.Context, segmentID int, ids []string, opts ...SegmentOption) error {
+	return s.segment(ctx, "RemovePeopleFromSegment", ids, func(ctx context.Context, c *CustomerIO, ids []string) error {
+		return c.RemovePeopleFromSegment(ctx, segmentID, ids, opts...)
+	})
+}
+
+func (s *ShadowClient) segment(ctx context.Context, op string, ids []string, fn func(context.Context, *CustomerIO, []string) error) error {
+	primaryFn := func(ctx context.Context, c *CustomerIO) error {
+		return fn(ctx, c, ids)
+	}
+	var shadowIDs []string
+	for _, id := range ids {
+		if s.shadowed(id) {
+			shadowIDs = append(shadowIDs, id)
+		}
+	}
+	if len(shadowIDs) == 0 {
+		return primaryFn(ctx, s.primary)
+	}
+	return s.run(ctx, op, "", primaryFn, func(ctx context.Context, c *CustomerIO) error {
+		return fn(ctx, c, shadowIDs)
+	})
+}
@@ -0,0 +1,215 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+// countingServer returns a Track client whose server responds with status
+// and counts the requests it receives.
+func countingServer(t *testing.T, status int) (*customerio.CustomerIO, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return customerio.NewTrackClient("siteid", "apikey", customerio.WithURL(srv.URL)), &requests
+}
+
+func TestShadowClientReturnsPrimaryResult(t *testing.T) {
+	primary, primaryRequests := countingServer(t, http.StatusOK)
+	shadow, shadowRequests := countingServer(t, http.StatusInternalServerError)
+
+	var mu sync.Mutex
+	var results []customerio.ShadowResult
+	s := customerio.NewShadowClient(primary, shadow, customerio.WithShadowRecorder(func(res customerio.ShadowResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, res)
+	}))
+
+	if err := s.Identify("1", map[string]any{"plan": "pro"}); err != nil {
+		t.Fatalf("unexpected primary error: %v", err)
+	}
+	if err := s.TrackCtx(context.Background(), "1", "purchase", nil); err != nil {
+		t.Fatalf("unexpected primary error: %v", err)
+	}
+	s.Wait()
+
+	if primaryRequests.Load() != 2 || shadowRequests.Load() != 2 {
+		t.Errorf("expected 2 requests each, got %d and %d", primaryRequests.Load(), shadowRequests.Load())
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	res := results[0]
+	var cerr *customerio.CustomerIOError
+	if res.Operation != "IdentifyCtx" || res.Key != "1" || res.PrimaryErr != nil || !errors.As(res.ShadowErr, &cerr) || !res.Mismatch() {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if stats := s.Stats(); stats.Shadowed != 2 || stats.ShadowFailures != 2 || stats.Mismatches != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestShadowClientPrimaryFailure(t *testing.T) {
+	primary, _ := countingServer(t, http.StatusBadRequest)
+	shadow, _ := countingServer(t, http.StatusOK)
+	s := customerio.NewShadowClient(primary, shadow)
+
+	if err := s.Delete("1"); err == nil {
+		t.Error("expected primary error")
+	}
+	s.Wait()
+	if stats := s.Stats(); stats.Shadowed != 1 || stats.ShadowFailures != 0 || stats.Mismatches != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestShadowClientDropsBeyondLimit(t *testing.T) {
+	primary, primaryRequests := countingServer(t, http.StatusOK)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	shadow := customerio.NewTrackClient("siteid", "apikey", customerio.WithURL(srv.URL))
+	s := customerio.NewShadowClient(primary, shadow, customerio.WithMaxShadowsInFlight(2))
+
+	for i := 0; i < 5; i++ {
+		if err := s.Identify("1", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := s.Stats().Dropped; got != 3 {
+		t.Errorf("expected 3 dropped shadows while 2 are blocked, got %d", got)
+	}
+	close(release)
+	s.Wait()
+
+	if stats := s.Stats(); stats.Shadowed != 2 || stats.Dropped != 3 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if primaryRequests.Load() != 5 {
+		t.Errorf("expected every call to reach the primary, got %d", primaryRequests.Load())
+	}
+}
+
+func TestShadowClientPercent(t *testing.T) {
+	primary, _ := countingServer(t, http.StatusOK)
+	shadow, shadowRequests := countingServer(t, http.StatusOK)
+
+	s := customerio.NewShadowClient(primary, shadow, customerio.WithShadowPercent(0))
+	for i := range 20 {
+		if err := s.Identify(fmt.Sprint(i), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Wait()
+	if shadowRequests.Load() != 0 {
+		t.Errorf("expected no shadow requests at 0%%, got %d", shadowRequests.Load())
+	}
+
+	// Customers shadowed at a lower percentage stay shadowed as it rises.
+	shadowedAt := func(percent float64) map[string]bool {
+		var mu sync.Mutex
+		shadowed := map[string]bool{}
+		s := customerio.NewShadowClient(primary, shadow,
+			customerio.WithShadowPercent(percent),
+			customerio.WithShadowRecorder(func(res customerio.ShadowResult) {
+				mu.Lock()
+				defer mu.Unlock()
+				shadowed[res.Key] = true
+			}))
+		for i := range 200 {
+			if err := s.Identify(fmt.Sprint(i), nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s.Wait()
+		return shadowed
+	}
+	low, high := shadowedAt(20), shadowedAt(60)
+	if len(low) == 0 || len(low) >= len(high) || len(high) == 200 {
+		t.Errorf("unexpected shadowed counts %d and %d", len(low), len(high))
+	}
+	for id := range low {
+		if !high[id] {
+			t.Errorf("customer %s shadowed at 20%% but not 60%%", id)
+		}
+	}
+}
+
+func TestShadowClientSegmentSendsShadowedIDs(t *testing.T) {
+	primary, _ := countingServer(t, http.StatusOK)
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			IDs []string `json:"ids"`
+		}
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		got = body.IDs
+	}))
+	t.Cleanup(srv.Close)
+	shadow := customerio.NewTrackClient("siteid", "apikey", customerio.WithURL(srv.URL))
+
+	s := customerio.NewShadowClient(primary, shadow, customerio.WithShadowPercent(50))
+	ids := make([]string, 100)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+	if err := s.AddPeopleToSegment(context.Background(), 4, ids); err != nil {
+		t.Fatal(err)
+	}
+	s.Wait()
+	if len(got) == 0 || len(got) == len(ids) {
+		t.Errorf("expected a subset of ids in the shadow, got %d", len(got))
+	}
+}
+
+func TestShadowClientPrimaryPanic(t *testing.T) {
+	primary := customerio.NewTrackClient("siteid", "apikey", customerio.WithHTTPClient(httpClientFunc(func(*http.Request) (*http.Response, error) {
+		panic("boom")
+	})))
+	shadow, _ := countingServer(t, http.StatusOK)
+
+	var results []customerio.ShadowResult
+	s := customerio.NewShadowClient(primary, shadow, customerio.WithShadowRecorder(func(res customerio.ShadowResult) {
+		results = append(results, res)
+	}))
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("expected the primary panic to propagate, got %v", r)
+			}
+		}()
+		_ = s.Identify("1", nil)
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		s.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait blocked after a primary panic")
+	}
+	if len(results) != 1 || results[0].PrimaryErr == nil || !results[0].Mismatch() {
+		t.Errorf("expected a recorded primary failure, got %+v", results)
+	}
+}
@@ -34,6 +34,17 @@ func (c *APIClient) sendTransactional(ctx context.Context, typ TransactionalType
 	if !ok {
 		return nil, ErrInvalidTransactionalMessageType
 	}
+	if v, ok := req.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if c.recipients != nil {
+		var err error
+		if req, err = c.recipients.transactional(req); err != nil {
+			return nil, err
+		}
+	}
 
 	body, statusCode, err := c.doRequest(ctx, "POST", formatPath("/v1/send/%s", api), req)
 	if err != nil {
@@ -62,16 +73,31 @@ func (c *APIClient) sendTransactional(ctx context.Context, typ TransactionalType
 	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, err
 	}
+	if sendAt := requestSendAt(req); sendAt != nil {
+		resp.ScheduledAt = time.Unix(*sendAt, 0)
+	}
 
 	return &resp, nil
 }
 
+// identifierMap validates id and converts it to the Identifiers field form.
+func identifierMap(id Identifier) (map[string]string, error) {
+	if err := id.validate(); err != nil {
+		return nil, fmt.Errorf("identifier: %w", err)
+	}
+	return id.kv(), nil
+}
+
 // TransactionalResponse  is a response to the send of a transactional message.
 type TransactionalResponse struct {
 	// DeliveryID is a unique id for the given message.
 	DeliveryID string `json:"delivery_id"`
 	// QueuedAt is when the message was queued.
 	QueuedAt time.Time `json:"queued_at"`
+	// ScheduledAt is when a message sent with a SendAt will be delivered, or
+	// the zero time if it is sent immediately. The API does not return the
+	// scheduled time, so this echoes the request's SendAt.
+	ScheduledAt time.Time `json:"-"`
 }
 
 func (t *TransactionalResponse) UnmarshalJSON(b []byte) error {
@@ -0,0 +1,223 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TransactionalMessage describes a transactional message template, the target
+// of a send request's TransactionalMessageID.
+type TransactionalMessage struct {
+	ID                 int       `json:"id"`
+	Name               string    `json:"name"`
+	Description        string    `json:"description"`
+	SendToUnsubscribed bool      `json:"send_to_unsubscribed"`
+	LinkTracking       bool      `json:"link_tracking"`
+	OpenTracking       bool      `json:"open_tracking"`
+	HideMessageBody    bool      `json:"hide_message_body"`
+	QueueDrafts        bool      `json:"queue_drafts"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+func (m *TransactionalMessage) UnmarshalJSON(b []byte) error {
+	type message TransactionalMessage
+	var r struct {
+		*message
+		CreatedAt int64 `json:"created_at"`
+		UpdatedAt int64 `json:"updated_at"`
+	}
+	r.message = (*message)(m)
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	m.CreatedAt = unixTime(r.CreatedAt)
+	m.UpdatedAt = unixTime(r.UpdatedAt)
+	return nil
+}
+
+// TransactionalMessageList is a page of transactional message templates.
+type TransactionalMessageList struct {
+	Messages []TransactionalMessage `json:"messages"`
+	// Next is the cursor for the following page, empty on the last page.
+	Next string `json:"next"`
+}
+
+// TransactionalContent is one content variant of a transactional message.
+// A message has one variant per language; the default variant has an empty
+// Language.
+type TransactionalContent struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Language      string    `json:"language"`
+	From          string    `json:"from"`
+	ReplyTo       string    `json:"reply_to"`
+	BCC           string    `json:"bcc"`
+	Subject       string    `json:"subject"`
+	Preheader     string    `json:"preheader"`
+	Body          string    `json:"body"`
+	PlaintextBody string    `json:"body_plain"`
+	AMPBody       string    `json:"body_amp"`
+	CreatedAt     time.Time `json:"created"`
+	UpdatedAt     time.Time `json:"updated"`
+}
+
+func (tc *TransactionalContent) UnmarshalJSON(b []byte) error {
+	type content TransactionalContent
+	var r struct {
+		*content
+		CreatedAt int64 `json:"created"`
+		UpdatedAt int64 `json:"updated"`
+	}
+	r.content = (*content)(tc)
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	tc.CreatedAt = unixTime(r.CreatedAt)
+	tc.UpdatedAt = unixTime(r.UpdatedAt)
+	return nil
+}
+
+// TransactionalContentUpdate holds the fields to change on a transactional
+// message content variant. Nil fields are left unchanged.
+type TransactionalContentUpdate struct {
+	From          *string `json:"from,omitempty"`
+	ReplyTo       *string `json:"reply_to,omitempty"`
+	BCC           *string `json:"bcc,omitempty"`
+	Subject       *string `json:"subject,omitempty"`
+	Preheader     *string `json:"preheader,omitempty"`
+	Body          *string `json:"body,omitempty"`
+	PlaintextBody *string `json:"body_plain,omitempty"`
+	AMPBody       *string `json:"body_amp,omitempty"`
+}
+
+// ErrTransactionalMessageNotFound is returned by VerifyTransactionalMessage
+// when the workspace has no transactional message with the given ID.
+var ErrTransactionalMessageNotFound = errors.New("transactional message not found")
+
+// MissingLanguagesError is returned by VerifyTransactionalMessage when a
+// transactional message has no content variant for some languages.
+type MissingLanguagesError struct {
+	MessageID string
+	Languages []string
+}
+
+func (e *MissingLanguagesError) Error() string {
+	return fmt.Sprintf("transactional message %s: missing languages: %s", e.MessageID, strings.Join(e.Languages, ", "))
+}
+
+// ListTransactionalMessages returns a page of the workspace's transactional messages.
+// See https://docs.customer.io/api/app/#operation/listTransactional
+func (c *APIClient) ListTransactionalMessages(ctx context.Context, page PageOptions) (*TransactionalMessageList, error) {
+	var list TransactionalMessageList
+	if err := c.requestJSON(ctx, "GET", withQuery("/v1/transactional", page.values()), nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetTransactionalContents returns every content variant of a transactional message.
+// See https://docs.customer.io/api/app/#operation/getTransactionalVariants
+func (c *APIClient) GetTransactionalContents(ctx context.Context, messageID string) ([]TransactionalContent, error) {
+	if messageID == "" {
+		return nil, ParamError{Param: "messageID"}
+	}
+
+	var resp struct {
+		Contents []TransactionalContent `json:"contents"`
+	}
+	if err := c.requestJSON(ctx, "GET", formatPath("/v1/transactional/%s/contents", messageID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Contents, nil
+}
+
+// UpdateTransactionalContent updates a single content variant of a transactional message.
+// See https://docs.customer.io/api/app/#operation/updateTransactional
+func (c *APIClient) UpdateTransactionalContent(ctx context.Context, messageID string, contentID int, update TransactionalContentUpdate) (*TransactionalContent, error) {
+	if messageID == "" {
+		return nil, ParamError{Param: "messageID"}
+	}
+	if contentID <= 0 {
+		return nil, ParamError{Param: "contentID"}
+	}
+
+	var resp struct {
+		Content TransactionalContent `json:"content"`
+	}
+	if err := c.requestJSON(ctx, "PUT", formatPath("/v1/transactional/%s/content/%d", messageID, contentID), update, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Content, nil
+}
+
+// GetTransactionalMetrics returns a transactional message's metrics over the queried time range.
+// See https://docs.customer.io/api/app/#operation/getTransactionalMetrics
+func (c *APIClient) GetTransactionalMetrics(ctx context.Context, messageID string, query MetricsQuery) (*Metrics, error) {
+	if messageID == "" {
+		return nil, ParamError{Param: "messageID"}
+	}
+
+	var resp metricsResponse
+	if err := c.requestJSON(ctx, "GET", withQuery(formatPath("/v1/transactional/%s/metrics", messageID), query.values()), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Metric.Series, nil
+}
+
+// ListTransactionalDeliveries returns a page of the deliveries sent from a transactional message.
+// See https://docs.customer.io/api/app/#operation/getTransactionalMessageDeliveries
+func (c *APIClient) ListTransactionalDeliveries(ctx context.Context, messageID string, page PageOptions) (*MessageList, error) {
+	if messageID == "" {
+		return nil, ParamError{Param: "messageID"}
+	}
+
+	var list MessageList
+	if err := c.requestJSON(ctx, "GET", withQuery(formatPath("/v1/transactional/%s/messages", messageID), page.values()), nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// VerifyTransactionalMessage checks that the transactional message referenced by
+// messageID exists and has a content variant for each of languages. It is
+// intended for deploy-time checks of the TransactionalMessageID and Language
+// values used in send requests. An empty language refers to the default variant.
+//
+// It returns an error wrapping ErrTransactionalMessageNotFound if the message
+// does not exist, or a *MissingLanguagesError listing every missing language.
+func (c *APIClient) VerifyTransactionalMessage(ctx context.Context, messageID string, languages ...string) error {
+	contents, err := c.GetTransactionalContents(ctx, messageID)
+	if err != nil {
+		var cerr *CustomerIOError
+		if errors.As(err, &cerr) && cerr.StatusCode() == http.StatusNotFound {
+			return fmt.Errorf("%w: %s", ErrTransactionalMessageNotFound, messageID)
+		}
+		return err
+	}
+	if len(contents) == 0 {
+		return fmt.Errorf("%w: %s", ErrTransactionalMessageNotFound, messageID)
+	}
+
+	have := make(map[string]bool, len(contents))
+	for _, content := range contents {
+		have[content.Language] = true
+	}
+
+	var missing []string
+	for _, lang := range languages {
+		if !have[lang] {
+			missing = append(missing, lang)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingLanguagesError{MessageID: messageID, Languages: missing}
+	}
+	return nil
+}
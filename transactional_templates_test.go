@@ -0,0 +1,157 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestListTransactionalMessages(t *testing.T) {
+	api := appServer(t, "GET", "/v1/transactional", `{"messages":[{"id":1,"name":"Password reset","open_tracking":true,"created_at":1500000000}]}`)
+
+	list, err := api.ListTransactionalMessages(context.Background(), customerio.PageOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &customerio.TransactionalMessageList{
+		Messages: []customerio.TransactionalMessage{{
+			ID:           1,
+			Name:         "Password reset",
+			OpenTracking: true,
+			CreatedAt:    time.Unix(1500000000, 0),
+		}},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("want %#v got %#v", want, list)
+	}
+}
+
+func TestGetTransactionalContents(t *testing.T) {
+	api := appServer(t, "GET", "/v1/transactional/reset%2Fpassword/contents", `{"contents":[{"id":2,"type":"email","language":"","subject":"Reset"},{"id":3,"type":"email","language":"fr","subject":"Réinitialiser"}]}`)
+
+	contents, err := api.GetTransactionalContents(context.Background(), "reset/password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []customerio.TransactionalContent{
+		{ID: 2, Type: "email", Subject: "Reset"},
+		{ID: 3, Type: "email", Language: "fr", Subject: "Réinitialiser"},
+	}
+	if !reflect.DeepEqual(contents, want) {
+		t.Errorf("want %#v got %#v", want, contents)
+	}
+
+	_, err = api.GetTransactionalContents(context.Background(), "")
+	checkParamError(t, err, "messageID")
+}
+
+func TestUpdateTransactionalContent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "PUT" || req.URL.Path != "/v1/transactional/1/content/2" {
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		}
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if string(b) != `{"subject":"New subject"}` {
+			t.Errorf("unexpected body %s", b)
+		}
+		_, _ = w.Write([]byte(`{"content":{"id":2,"subject":"New subject"}}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	subject := "New subject"
+	content, err := api.UpdateTransactionalContent(context.Background(), "1", 2, customerio.TransactionalContentUpdate{Subject: &subject})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content.ID != 2 || content.Subject != subject {
+		t.Errorf("unexpected content %#v", content)
+	}
+
+	_, err = api.UpdateTransactionalContent(context.Background(), "1", 0, customerio.TransactionalContentUpdate{})
+	checkParamError(t, err, "contentID")
+}
+
+func TestGetTransactionalMetrics(t *testing.T) {
+	api := appServer(t, "GET", "/v1/transactional/1/metrics?res=hours", `{"metric":{"series":{"sent":[1,2],"delivered":[1,1]}}}`)
+
+	metrics, err := api.GetTransactionalMetrics(context.Background(), "1", customerio.MetricsQuery{Period: customerio.MetricsPeriodHours})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(metrics.Sent, []int{1, 2}) || !reflect.DeepEqual(metrics.Delivered, []int{1, 1}) {
+		t.Errorf("unexpected metrics %#v", metrics)
+	}
+}
+
+func TestListTransactionalDeliveries(t *testing.T) {
+	api := appServer(t, "GET", "/v1/transactional/1/messages?limit=10", `{"messages":[{"id":"dlv_1","type":"email","recipient":"a@example.com","metrics":{"sent":1500000000,"delivered":1500000005}}],"next":"x"}`)
+
+	list, err := api.ListTransactionalDeliveries(context.Background(), "1", customerio.PageOptions{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &customerio.MessageList{
+		Messages: []customerio.Message{{
+			ID:        "dlv_1",
+			Type:      "email",
+			Recipient: "a@example.com",
+			Metrics: customerio.MessageMetrics{
+				Sent:      time.Unix(1500000000, 0),
+				Delivered: time.Unix(1500000005, 0),
+			},
+		}},
+		Next: "x",
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("want %#v got %#v", want, list)
+	}
+}
+
+func TestVerifyTransactionalMessage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/transactional/1/contents" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"contents": []map[string]any{{"id": 2, "language": ""}, {"id": 3, "language": "fr"}},
+		})
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+	ctx := context.Background()
+
+	if err := api.VerifyTransactionalMessage(ctx, "1", "", "fr"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := api.VerifyTransactionalMessage(ctx, "1", "fr", "de", "es")
+	var mle *customerio.MissingLanguagesError
+	if !errors.As(err, &mle) {
+		t.Fatalf("expected MissingLanguagesError, got %v", err)
+	}
+	if !reflect.DeepEqual(mle.Languages, []string{"de", "es"}) {
+		t.Errorf("unexpected missing languages %v", mle.Languages)
+	}
+
+	if err := api.VerifyTransactionalMessage(ctx, "2"); !errors.Is(err, customerio.ErrTransactionalMessageNotFound) {
+		t.Errorf("expected ErrTransactionalMessageNotFound, got %v", err)
+	}
+}
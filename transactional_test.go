@@ -1,11 +1,15 @@
 package customerio_test
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/customerio/go-customerio/v3"
 )
@@ -40,3 +44,130 @@ func transactionalServer(t *testing.T, verify func(request []byte)) (*customerio
 
 	return api, srv
 }
+
+func TestTransactionalIdentifiersValidated(t *testing.T) {
+	api, srv := transactionalServer(t, func(request []byte) {
+		t.Errorf("unexpected request %s", request)
+	})
+	defer srv.Close()
+	ctx := context.Background()
+
+	cases := map[string]map[string]string{
+		"none":     nil,
+		"multiple": {"id": "1", "email": "a@example.com"},
+		"bad type": {"Id": "1"},
+		"blank":    {"id": " "},
+	}
+	for name, ids := range cases {
+		t.Run(name, func(t *testing.T) {
+			sends := map[string]func() error{
+				"email": func() error {
+					_, err := api.SendEmail(ctx, &customerio.SendEmailRequest{TransactionalMessageID: "1", Identifiers: ids})
+					return err
+				},
+				"sms": func() error {
+					_, err := api.SendSMS(ctx, &customerio.SendSMSRequest{TransactionalMessageID: "1", Identifiers: ids})
+					return err
+				},
+				"push": func() error {
+					_, err := api.SendPush(ctx, &customerio.SendPushRequest{TransactionalMessageID: "1", Identifiers: ids})
+					return err
+				},
+				"in-app": func() error {
+					_, err := api.SendInApp(ctx, &customerio.SendInAppRequest{TransactionalMessageID: "1", Identifiers: ids})
+					return err
+				},
+				"inbox": func() error {
+					_, err := api.SendInboxMessage(ctx, &customerio.SendInboxMessageRequest{TransactionalMessageID: "1", Identifiers: ids})
+					return err
+				},
+			}
+			for kind, send := range sends {
+				var verr *customerio.ValidationError
+				if err := send(); !errors.As(err, &verr) || len(verr.Errors) != 1 || verr.Errors[0].Field != "identifiers" {
+					t.Errorf("%s: expected a single identifiers field error, got %v", kind, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSetIdentifier(t *testing.T) {
+	var req customerio.SendEmailRequest
+	if err := req.SetIdentifier(customerio.Identifier{Type: customerio.IdentifierTypeEmail, Value: "a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(req.Identifiers, map[string]string{"email": "a@example.com"}) {
+		t.Errorf("unexpected identifiers %v", req.Identifiers)
+	}
+
+	if err := req.SetIdentifier(customerio.Identifier{Type: "Id", Value: "1"}); err == nil {
+		t.Error("expected error for invalid type")
+	}
+	var sms customerio.SendSMSRequest
+	if err := sms.SetIdentifier(customerio.Identifier{Type: customerio.IdentifierTypeCioID, Value: ""}); err == nil {
+		t.Error("expected error for blank value")
+	}
+	if sms.Identifiers != nil {
+		t.Errorf("identifiers set despite error: %v", sms.Identifiers)
+	}
+}
+
+func TestTransactionalRequestValidate(t *testing.T) {
+	past := time.Now().Add(-time.Hour).Unix()
+	future := time.Now().Add(time.Hour).Unix()
+	bad, good := "not a language", "pt-BR"
+	id := map[string]string{"id": "1"}
+
+	cases := map[string]struct {
+		req    interface{ Validate() error }
+		fields []string
+	}{
+		"email template":  {&customerio.SendEmailRequest{TransactionalMessageID: "1", Identifiers: id, SendAt: &future, Language: &good}, nil},
+		"email inline":    {&customerio.SendEmailRequest{Identifiers: id, Body: "b", Subject: "s", From: "f@example.com"}, nil},
+		"email empty":     {&customerio.SendEmailRequest{}, []string{"identifiers", "body", "subject", "from"}},
+		"email common":    {&customerio.SendEmailRequest{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "1", "email": "e"}, SendAt: &past, Language: &bad}, []string{"identifiers", "send_at", "language"}},
+		"push template":   {&customerio.SendPushRequest{TransactionalMessageID: "1", Identifiers: id}, nil},
+		"push inline":     {&customerio.SendPushRequest{Identifiers: id, Message: "hi"}, nil},
+		"push empty":      {&customerio.SendPushRequest{Identifiers: map[string]string{"Id": "1"}}, []string{"identifiers", "message"}},
+		"sms":             {&customerio.SendSMSRequest{TransactionalMessageID: "1", Identifiers: id}, nil},
+		"sms no template": {&customerio.SendSMSRequest{Identifiers: id, Language: &bad}, []string{"language", "transactional_message_id"}},
+		"in-app":          {&customerio.SendInAppRequest{Identifiers: id, SendAt: &past}, []string{"send_at", "transactional_message_id"}},
+		"inbox":           {&customerio.SendInboxMessageRequest{TransactionalMessageID: "1"}, []string{"identifiers"}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.req.Validate()
+			if tc.fields == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var verr *customerio.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			var fields []string
+			for _, f := range verr.Errors {
+				fields = append(fields, f.Field)
+			}
+			if !reflect.DeepEqual(fields, tc.fields) {
+				t.Errorf("got fields %v, want %v", fields, tc.fields)
+			}
+		})
+	}
+}
+
+func TestSendValidatesBeforeSending(t *testing.T) {
+	api, srv := transactionalServer(t, func(request []byte) {
+		t.Errorf("unexpected request %s", request)
+	})
+	defer srv.Close()
+
+	_, err := api.SendPush(context.Background(), &customerio.SendPushRequest{Identifiers: map[string]string{"id": "1"}})
+	var ferr customerio.FieldError
+	if !errors.As(err, &ferr) || ferr.Field != "message" {
+		t.Errorf("expected message FieldError, got %v", err)
+	}
+}
@@ -1,11 +1,34 @@
 package customerio
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 )
 
+// BroadcastTriggerRateLimit is how often Customer.io accepts repeat triggers
+// of the same broadcast. Pass it to WithBroadcastTriggerInterval to pace the
+// chunks of a large TriggerBroadcast call to match.
+const BroadcastTriggerRateLimit = 10 * time.Second
+
+// WithBroadcastTriggerInterval makes TriggerBroadcast pause for d between the
+// triggers of a broadcast whose recipients were split into several chunks,
+// blocking the caller for d per extra chunk. By default the chunks are sent
+// back to back, leaving the caller to pace them.
+func WithBroadcastTriggerInterval(d time.Duration) Option {
+	if d < 0 {
+		return option{err: fmt.Errorf("broadcast trigger interval %s is negative", d)}
+	}
+	return option{
+		api: func(a *APIClient) {
+			a.triggerInterval = d
+		},
+	}
+}
+
 // BroadcastRecipients defines who receives a broadcast trigger.
 // Set Segment for segment-based targeting, or set exactly one of
 // Ids, Emails, PerUserData, or DataFileURL for direct targeting.
@@ -29,7 +52,11 @@ type BroadcastOptions struct {
 
 // BroadcastResponse is returned when a broadcast is successfully triggered.
 type BroadcastResponse struct {
+	// ID is the trigger ID. When the recipients were split across several
+	// triggers it is the ID of the first successful one.
 	ID int `json:"id"`
+	// TriggerIDs lists the ID of every successful trigger, in chunk order.
+	TriggerIDs []int `json:"-"`
 }
 
 // broadcastInput bundles the inputs to buildBroadcastPayload.
@@ -60,33 +87,147 @@ type broadcastPayload struct {
 // of recipients.Ids, recipients.Emails, recipients.PerUserData, or recipients.DataFileURL.
 // opts.IDIgnoreMissing/EmailIgnoreMissing/EmailAddDuplicates apply only to direct
 // targeting and are filtered to the recipient type in use.
+//
+// More than MaxBroadcastRecipients direct recipients are sent as several sequential
+// triggers, back to back unless the client was configured with
+// WithBroadcastTriggerInterval, in which case the call blocks for the interval
+// between each of them. If any of them fail, the
+// response for the successful triggers is returned together with a
+// *PartialFailureError. Once ctx is done, the remaining chunks are not sent and are
+// reported as failed with the context's error.
+//
+// Clients configured with WithRecipientPolicy check the recipients first.
 func (c *APIClient) TriggerBroadcast(ctx context.Context, broadcastID int, data map[string]any, recipients BroadcastRecipients, opts BroadcastOptions) (*BroadcastResponse, error) {
 	if broadcastID <= 0 {
 		return nil, ParamError{Param: "broadcastID"}
 	}
+	if c.recipients != nil {
+		var err error
+		if recipients, err = c.recipients.broadcast(recipients); err != nil {
+			return nil, err
+		}
+	}
+
+	chunks := chunkBroadcastRecipients(recipients, MaxBroadcastRecipients)
+	if len(chunks) == 1 {
+		id, err := c.triggerBroadcast(ctx, broadcastID, broadcastInput{Data: data, Recipients: recipients, Options: opts})
+		if err != nil {
+			return nil, err
+		}
+		return &BroadcastResponse{ID: id, TriggerIDs: []int{id}}, nil
+	}
+
+	var resp BroadcastResponse
+	var failed []ChunkError
+	for i, part := range chunks {
+		if i > 0 && c.triggerInterval > 0 {
+			pause := time.NewTimer(c.triggerInterval)
+			select {
+			case <-pause.C:
+			case <-ctx.Done():
+				pause.Stop()
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			for j, rest := range chunks[i:] {
+				failed = append(failed, ChunkError{Index: i + j, IDs: rest.ids(), Err: err})
+			}
+			break
+		}
+		id, err := c.triggerBroadcast(ctx, broadcastID, broadcastInput{Data: data, Recipients: part, Options: opts})
+		if err != nil {
+			failed = append(failed, ChunkError{Index: i, IDs: part.ids(), Err: err})
+			continue
+		}
+		if len(resp.TriggerIDs) == 0 {
+			resp.ID = id
+		}
+		resp.TriggerIDs = append(resp.TriggerIDs, id)
+	}
+
+	if len(failed) > 0 {
+		err := &PartialFailureError{Chunks: len(chunks), Failed: failed}
+		if len(resp.TriggerIDs) == 0 {
+			return nil, err
+		}
+		return &resp, err
+	}
+	return &resp, nil
+}
 
-	payload := buildBroadcastPayload(broadcastInput{Data: data, Recipients: recipients, Options: opts})
+func (c *APIClient) triggerBroadcast(ctx context.Context, broadcastID int, in broadcastInput) (int, error) {
+	payload := buildBroadcastPayload(in)
 
 	requestPath := formatPath("/v1/campaigns/%d/triggers", broadcastID)
 	body, statusCode, err := c.doRequest(ctx, "POST", requestPath, payload)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
 	if statusCode != http.StatusOK {
-		return nil, &CustomerIOError{
+		return 0, &CustomerIOError{
 			status: statusCode,
 			url:    c.URL + requestPath,
 			body:   body,
 		}
 	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return 0, fmt.Errorf("%w: %d from %s", ErrEmptyResponse, statusCode, c.URL+requestPath)
+	}
 
 	var resp BroadcastResponse
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	return &resp, nil
+	return resp.ID, nil
+}
+
+// chunkBroadcastRecipients splits the direct recipient list in r into
+// recipient sets of at most size entries. Segment and DataFileURL targeting
+// are never split.
+func chunkBroadcastRecipients(r BroadcastRecipients, size int) []BroadcastRecipients {
+	var out []BroadcastRecipients
+	switch {
+	case len(r.Ids) > size:
+		for _, part := range chunk(r.Ids, size) {
+			out = append(out, BroadcastRecipients{Ids: part})
+		}
+	case len(r.Ids) == 0 && len(r.Emails) > size:
+		for _, part := range chunk(r.Emails, size) {
+			out = append(out, BroadcastRecipients{Emails: part})
+		}
+	case len(r.Ids) == 0 && len(r.Emails) == 0 && len(r.PerUserData) > size:
+		for _, part := range chunk(r.PerUserData, size) {
+			out = append(out, BroadcastRecipients{PerUserData: part})
+		}
+	default:
+		out = append(out, r)
+	}
+	return out
+}
+
+// ids returns a printable identifier for every direct recipient in r, used to
+// report which recipients belonged to a failed chunk.
+func (r BroadcastRecipients) ids() []string {
+	switch {
+	case len(r.Ids) > 0:
+		return r.Ids
+	case len(r.Emails) > 0:
+		return r.Emails
+	}
+	ids := make([]string, 0, len(r.PerUserData))
+	for _, u := range r.PerUserData {
+		switch {
+		case u["id"] != nil:
+			ids = append(ids, fmt.Sprint(u["id"]))
+		case u["email"] != nil:
+			ids = append(ids, fmt.Sprint(u["email"]))
+		default:
+			ids = append(ids, "")
+		}
+	}
+	return ids
 }
 
 // If a direct recipient field (ids, emails, per_user_data, data_file_url) is present,
@@ -3,6 +3,7 @@ package customerio_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/customerio/go-customerio/v3"
 )
@@ -356,3 +358,154 @@ func TestTriggerBroadcastCompanionOptionsIsolation(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestTriggerBroadcastChunksLargeRecipientLists(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var payload struct {
+			Emails []string `json:"emails"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		calls++
+		if calls == 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if len(payload.Emails) > customerio.MaxBroadcastRecipients {
+			t.Errorf("chunk too large: %d", len(payload.Emails))
+		}
+		_, _ = fmt.Fprintf(w, `{"id":%d}`, calls)
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	emails := make([]string, 2*customerio.MaxBroadcastRecipients+5)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("user%d@example.com", i)
+	}
+
+	resp, err := api.TriggerBroadcast(context.Background(), 1, nil, customerio.BroadcastRecipients{Emails: emails}, customerio.BroadcastOptions{})
+	if calls != 3 {
+		t.Fatalf("expected 3 triggers, got %d", calls)
+	}
+
+	var pe *customerio.PartialFailureError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected PartialFailureError, got %#v", err)
+	}
+	if len(pe.Failed) != 1 || pe.Failed[0].Index != 1 {
+		t.Fatalf("unexpected failure report: %v", pe)
+	}
+	if got := pe.FailedIDs(); !reflect.DeepEqual(got, emails[customerio.MaxBroadcastRecipients:2*customerio.MaxBroadcastRecipients]) {
+		t.Errorf("unexpected failed ids: %d ids", len(got))
+	}
+
+	if resp == nil {
+		t.Fatal("expected response for successful chunks")
+	}
+	if resp.ID != 1 || !reflect.DeepEqual(resp.TriggerIDs, []int{1, 3}) {
+		t.Errorf("unexpected response: %#v", resp)
+	}
+}
+
+func TestTriggerBroadcastPacesChunks(t *testing.T) {
+	var calls []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls = append(calls, time.Now())
+		_, _ = fmt.Fprintf(w, `{"id":%d}`, len(calls))
+	}))
+	defer srv.Close()
+
+	interval := 50 * time.Millisecond
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL), customerio.WithBroadcastTriggerInterval(interval))
+	ids := make([]string, 2*customerio.MaxBroadcastRecipients+1)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+
+	if _, err := api.TriggerBroadcast(context.Background(), 1, nil, customerio.BroadcastRecipients{Ids: ids}, customerio.BroadcastOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 triggers, got %d", len(calls))
+	}
+	for i := 1; i < len(calls); i++ {
+		if gap := calls[i].Sub(calls[i-1]); gap < interval {
+			t.Errorf("trigger %d sent %s after the previous one, want at least %s", i, gap, interval)
+		}
+	}
+
+	if _, err := customerio.NewAPIClientE("myKey", customerio.WithBroadcastTriggerInterval(-time.Second)); err == nil {
+		t.Error("expected an error for a negative interval")
+	}
+}
+
+func TestTriggerBroadcastStopsWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls int
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		cancel()
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"id":1}`))}, nil
+	})
+
+	api := customerio.NewAPIClient("myKey", customerio.WithHTTPClient(client), customerio.WithBroadcastTriggerInterval(time.Hour))
+	ids := make([]string, 2*customerio.MaxBroadcastRecipients+1)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+
+	resp, err := api.TriggerBroadcast(ctx, 1, nil, customerio.BroadcastRecipients{Ids: ids}, customerio.BroadcastOptions{})
+	if calls != 1 {
+		t.Fatalf("expected 1 trigger before cancellation, got %d", calls)
+	}
+	var pe *customerio.PartialFailureError
+	if !errors.As(err, &pe) || len(pe.Failed) != 2 || pe.Failed[0].Index != 1 || pe.Failed[1].Index != 2 {
+		t.Fatalf("expected the remaining chunks to fail, got %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if resp == nil || !reflect.DeepEqual(resp.TriggerIDs, []int{1}) {
+		t.Errorf("unexpected response %#v", resp)
+	}
+}
+
+func TestTriggerBroadcastRequiresResponseBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+	resp, err := api.TriggerBroadcast(context.Background(), 1, nil, customerio.BroadcastRecipients{Ids: []string{"1"}}, customerio.BroadcastOptions{})
+	if !errors.Is(err, customerio.ErrEmptyResponse) {
+		t.Errorf("expected ErrEmptyResponse, got %v", err)
+	}
+	if resp != nil {
+		t.Errorf("unexpected response %#v", resp)
+	}
+}
+
+func TestTriggerBroadcastRequiresStatusOK(t *testing.T) {
+	for _, status := range []int{http.StatusAccepted, http.StatusNoContent} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(status)
+			if status != http.StatusNoContent {
+				_, _ = w.Write([]byte(`{"id":1}`))
+			}
+		}))
+		api := customerio.NewAPIClient("myKey", customerio.WithURL(srv.URL))
+
+		_, err := api.TriggerBroadcast(context.Background(), 1, nil, customerio.BroadcastRecipients{Ids: []string{"1"}}, customerio.BroadcastOptions{})
+		var apiErr *customerio.CustomerIOError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode() != status {
+			t.Errorf("%d: expected a CustomerIOError, got %v", status, err)
+		}
+		srv.Close()
+	}
+}
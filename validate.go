@@ -0,0 +1,88 @@
+package customerio
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FieldError describes one invalid field of a request.
+type FieldError struct {
+	// Field is the JSON name of the field, such as "transactional_message_id".
+	Field string
+	// Message explains what is wrong with the field.
+	Message string
+}
+
+func (e FieldError) Error() string { return e.Field + ": " + e.Message }
+
+// ValidationError is returned by the Validate methods of request types, and
+// by the methods that send them, listing every problem found.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, f := range e.Errors {
+		msgs[i] = f.Error()
+	}
+	return "customerio: invalid request: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns every field error so errors.As can match them.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, f := range e.Errors {
+		errs[i] = f
+	}
+	return errs
+}
+
+// validator is implemented by request types that can check themselves
+// before being sent.
+type validator interface {
+	Validate() error
+}
+
+// fieldErrors accumulates the problems found while validating a request.
+type fieldErrors []FieldError
+
+func (f *fieldErrors) add(field, format string, args ...any) {
+	*f = append(*f, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (f fieldErrors) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: f}
+}
+
+// languageTag matches BCP 47 style tags such as "fr", "pt-BR" or "zh-Hant".
+var languageTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+// transactional checks the fields shared by every transactional request.
+func (f *fieldErrors) transactional(ids map[string]string, sendAt *int64, language *string) {
+	switch {
+	case len(ids) == 0:
+		f.add("identifiers", "missing")
+	case len(ids) > 1:
+		f.add("identifiers", "got %d identifiers, want exactly one", len(ids))
+	default:
+		for k, v := range ids {
+			if err := (Identifier{Type: IdentifierType(k), Value: v}).validate(); err != nil {
+				f.add("identifiers", "%v", err)
+			}
+		}
+	}
+	if sendAt != nil {
+		if msg := checkSendAt(*sendAt, time.Now(), sendAtSkew); msg != "" {
+			f.add("send_at", "%s", msg)
+		}
+	}
+	if language != nil && !languageTag.MatchString(*language) {
+		f.add("language", "invalid language tag %q", *language)
+	}
+}
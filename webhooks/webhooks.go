@@ -0,0 +1,285 @@
+// Package webhooks receives Customer.io reporting webhooks.
+//
+// Customer.io posts a JSON event to a reporting webhook each time a message
+// metric is recorded. Handler verifies the X-CIO-Signature and X-CIO-Timestamp
+// headers against the webhook's signing secret, decodes the event and passes
+// it to the func registered for its metric. See
+// https://docs.customer.io/journeys/webhooks/#securely-verify-requests
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the hex encoded HMAC-SHA256 of the request.
+	SignatureHeader = "X-CIO-Signature"
+	// TimestampHeader carries the Unix time the request was signed at.
+	TimestampHeader = "X-CIO-Timestamp"
+
+	// DefaultTolerance is the maximum age of a request accepted by a Handler
+	// created without WithTolerance.
+	DefaultTolerance = 5 * time.Minute
+
+	maxBodySize = 1 << 20
+)
+
+var (
+	// ErrMissingSignature is returned when the signature or timestamp header
+	// is empty.
+	ErrMissingSignature = errors.New("webhooks: missing signature or timestamp")
+	// ErrInvalidTimestamp is returned when the timestamp header is not a Unix
+	// timestamp.
+	ErrInvalidTimestamp = errors.New("webhooks: invalid timestamp")
+	// ErrInvalidSignature is returned when the signature does not match the
+	// body.
+	ErrInvalidSignature = errors.New("webhooks: invalid signature")
+	// ErrStaleTimestamp is returned when the timestamp is further from the
+	// current time than the tolerance allows.
+	ErrStaleTimestamp = errors.New("webhooks: timestamp outside tolerance")
+)
+
+// ObjectType is the kind of message an event reports on.
+type ObjectType string
+
+const (
+	ObjectTypeCustomer ObjectType = "customer"
+	ObjectTypeEmail    ObjectType = "email"
+	ObjectTypePush     ObjectType = "push"
+	ObjectTypeSMS      ObjectType = "sms"
+	ObjectTypeInApp    ObjectType = "in_app"
+	ObjectTypeWebhook  ObjectType = "webhook"
+)
+
+// Metric is the message state an event reports.
+type Metric string
+
+const (
+	MetricSent         Metric = "sent"
+	MetricDelivered    Metric = "delivered"
+	MetricOpened       Metric = "opened"
+	MetricClicked      Metric = "clicked"
+	MetricConverted    Metric = "converted"
+	MetricBounced      Metric = "bounced"
+	MetricSpammed      Metric = "spammed"
+	MetricUnsubscribed Metric = "unsubscribed"
+	MetricFailed       Metric = "failed"
+	MetricDropped      Metric = "dropped"
+)
+
+// Event is a single reporting webhook event.
+type Event struct {
+	// ID uniquely identifies the event; Customer.io may deliver an event more
+	// than once, so handlers should use it to deduplicate.
+	ID         string     `json:"event_id"`
+	ObjectType ObjectType `json:"object_type"`
+	Metric     Metric     `json:"metric"`
+	Timestamp  time.Time  `json:"timestamp"`
+	Data       EventData  `json:"data"`
+}
+
+func (e *Event) UnmarshalJSON(b []byte) error {
+	type event Event
+	var r struct {
+		*event
+		Timestamp int64 `json:"timestamp"`
+	}
+	r.event = (*event)(e)
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	e.Timestamp = time.Time{}
+	if r.Timestamp != 0 {
+		e.Timestamp = time.Unix(r.Timestamp, 0)
+	}
+	return nil
+}
+
+// EventData holds the details of an event. Fields that don't apply to the
+// event's object type or metric are empty; Raw holds the complete data object
+// for fields not modelled here.
+type EventData struct {
+	DeliveryID             string            `json:"delivery_id"`
+	CustomerID             string            `json:"customer_id"`
+	Identifiers            map[string]string `json:"identifiers"`
+	CampaignID             int               `json:"campaign_id"`
+	BroadcastID            int               `json:"broadcast_id"`
+	ActionID               int               `json:"action_id"`
+	TransactionalMessageID int               `json:"transactional_message_id"`
+	// Recipient is the email address or phone number for email and SMS events.
+	Recipient string `json:"recipient"`
+	// Recipients lists the devices of a push event.
+	Recipients []PushRecipient `json:"recipients"`
+	Subject    string          `json:"subject"`
+	// Href and LinkID identify the clicked link of a clicked event.
+	Href   string `json:"href"`
+	LinkID int    `json:"link_id"`
+	// FailureMessage explains a bounced, failed or dropped event.
+	FailureMessage string `json:"failure_message"`
+
+	Raw json.RawMessage `json:"-"`
+}
+
+func (d *EventData) UnmarshalJSON(b []byte) error {
+	type data EventData
+	if err := json.Unmarshal(b, (*data)(d)); err != nil {
+		return err
+	}
+	d.Raw = append(json.RawMessage(nil), b...)
+	return nil
+}
+
+// PushRecipient is a device targeted by a push event.
+type PushRecipient struct {
+	DeviceID       string `json:"device_id"`
+	DevicePlatform string `json:"device_platform"`
+}
+
+// HandlerFunc handles a verified event. Returning an error responds with a
+// 500 so Customer.io retries the delivery.
+type HandlerFunc func(ctx context.Context, e *Event) error
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithTolerance sets the maximum age, and clock skew, accepted for a request's
+// timestamp.
+func WithTolerance(d time.Duration) Option {
+	return func(h *Handler) {
+		h.tolerance = d
+	}
+}
+
+// WithFallback sets the func that handles events whose metric has no handler
+// registered with On. Without a fallback such events are acknowledged and
+// discarded.
+func WithFallback(fn HandlerFunc) Option {
+	return func(h *Handler) {
+		h.fallback = fn
+	}
+}
+
+// Handler is an http.Handler that verifies and dispatches reporting webhook events.
+type Handler struct {
+	secret    []byte
+	tolerance time.Duration
+	handlers  map[Metric]HandlerFunc
+	fallback  HandlerFunc
+}
+
+// NewHandler returns a Handler verifying requests with the webhook's signing secret.
+func NewHandler(secret string, opts ...Option) *Handler {
+	h := &Handler{
+		secret:    []byte(secret),
+		tolerance: DefaultTolerance,
+		handlers:  map[Metric]HandlerFunc{},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(h)
+		}
+	}
+	return h
+}
+
+// On registers fn to handle events for metric, replacing any previous handler.
+// It must not be called concurrently with ServeHTTP.
+func (h *Handler) On(metric Metric, fn HandlerFunc) {
+	h.handlers[metric] = fn
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, "reading request body failed", http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := verifyAt(h.secret, req.Header.Get(TimestampHeader), req.Header.Get(SignatureHeader), body, time.Now(), h.tolerance); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var e Event
+	if err := json.Unmarshal(body, &e); err != nil {
+		http.Error(w, "invalid event payload", http.StatusBadRequest)
+		return
+	}
+
+	fn := h.handlers[e.Metric]
+	if fn == nil {
+		fn = h.fallback
+	}
+	if fn != nil {
+		if err := fn(req.Context(), &e); err != nil {
+			http.Error(w, "event handler failed", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Verify checks a request's X-CIO-Timestamp and X-CIO-Signature header values
+// against its body, rejecting timestamps more than tolerance away from now.
+// Handler calls it for every request; it is exported for receivers that don't
+// use Handler.
+func Verify(secret, timestamp, signature string, body []byte, tolerance time.Duration) error {
+	return verifyAt([]byte(secret), timestamp, signature, body, time.Now(), tolerance)
+}
+
+func verifyAt(secret []byte, timestamp, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	if timestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+		return ErrStaleTimestamp
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal(got, sign(secret, timestamp, body)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign returns the X-CIO-Signature header value for body signed at timestamp.
+// It is useful for testing webhook receivers.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return hex.EncodeToString(sign([]byte(secret), strconv.FormatInt(timestamp.Unix(), 10), body))
+}
+
+func sign(secret []byte, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = io.WriteString(mac, "v0:"+timestamp+":")
+	_, _ = mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,167 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/customerio/go-customerio/v3/webhooks"
+)
+
+const testSecret = "shh"
+
+const deliveredEvent = `{
+	"event_id": "01E2EMRMM6TZ12TF9WGZN0WJQT",
+	"object_type": "email",
+	"metric": "delivered",
+	"timestamp": 1613063089,
+	"data": {
+		"delivery_id": "RPILAgUBcRhIBqSfeiIwdIYJKxTY",
+		"customer_id": "42",
+		"identifiers": {"id": "42", "email": "lucy@example.com"},
+		"transactional_message_id": 3,
+		"recipient": "lucy@example.com",
+		"subject": "Welcome",
+		"journey_id": "abc"
+	}
+}`
+
+func signedRequest(t *testing.T, body string, ts time.Time, secret string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/webhooks/cio", bytes.NewBufferString(body))
+	req.Header.Set(webhooks.TimestampHeader, strconv.FormatInt(ts.Unix(), 10))
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign(secret, ts, []byte(body)))
+	return req
+}
+
+func TestHandlerDispatchesByMetric(t *testing.T) {
+	var got *webhooks.Event
+	h := webhooks.NewHandler(testSecret)
+	h.On(webhooks.MetricDelivered, func(_ context.Context, e *webhooks.Event) error {
+		got = e
+		return nil
+	})
+	h.On(webhooks.MetricBounced, func(context.Context, *webhooks.Event) error {
+		t.Error("bounced handler called for delivered event")
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, signedRequest(t, deliveredEvent, time.Now(), testSecret))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %s", w.Code, w.Body)
+	}
+	if got == nil {
+		t.Fatal("delivered handler not called")
+	}
+	if got.ID != "01E2EMRMM6TZ12TF9WGZN0WJQT" || got.ObjectType != webhooks.ObjectTypeEmail || got.Metric != webhooks.MetricDelivered {
+		t.Errorf("unexpected event %#v", got)
+	}
+	if !got.Timestamp.Equal(time.Unix(1613063089, 0)) {
+		t.Errorf("unexpected timestamp %v", got.Timestamp)
+	}
+	if got.Data.DeliveryID != "RPILAgUBcRhIBqSfeiIwdIYJKxTY" || got.Data.Recipient != "lucy@example.com" || got.Data.TransactionalMessageID != 3 || got.Data.Identifiers["email"] != "lucy@example.com" {
+		t.Errorf("unexpected data %#v", got.Data)
+	}
+	if !bytes.Contains(got.Data.Raw, []byte(`"journey_id": "abc"`)) {
+		t.Errorf("expected raw data to be retained, got %s", got.Data.Raw)
+	}
+}
+
+func TestHandlerRejectsBadRequests(t *testing.T) {
+	h := webhooks.NewHandler(testSecret, webhooks.WithFallback(func(context.Context, *webhooks.Event) error {
+		t.Error("handler called for rejected request")
+		return nil
+	}))
+
+	unsigned := httptest.NewRequest("POST", "/", bytes.NewBufferString(deliveredEvent))
+
+	tampered := signedRequest(t, deliveredEvent, time.Now(), testSecret)
+	tampered.Body = http.NoBody
+
+	large := signedRequest(t, deliveredEvent, time.Now(), testSecret)
+	large.Body = io.NopCloser(strings.NewReader(strings.Repeat(" ", 2<<20)))
+
+	unreadable := signedRequest(t, deliveredEvent, time.Now(), testSecret)
+	unreadable.Body = io.NopCloser(iotest.ErrReader(errors.New("connection reset")))
+
+	cases := map[string]struct {
+		req  *http.Request
+		code int
+	}{
+		"unsigned":     {unsigned, http.StatusUnauthorized},
+		"wrong secret": {signedRequest(t, deliveredEvent, time.Now(), "other"), http.StatusUnauthorized},
+		"stale":        {signedRequest(t, deliveredEvent, time.Now().Add(-time.Hour), testSecret), http.StatusUnauthorized},
+		"future":       {signedRequest(t, deliveredEvent, time.Now().Add(time.Hour), testSecret), http.StatusUnauthorized},
+		"tampered":     {tampered, http.StatusUnauthorized},
+		"bad json":     {signedRequest(t, "{", time.Now(), testSecret), http.StatusBadRequest},
+		"get":          {httptest.NewRequest("GET", "/", nil), http.StatusMethodNotAllowed},
+		"too large":    {large, http.StatusRequestEntityTooLarge},
+		"unreadable":   {unreadable, http.StatusBadRequest},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, c.req)
+			if w.Code != c.code {
+				t.Errorf("expected %d got %d", c.code, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandlerErrorRequestsRetry(t *testing.T) {
+	h := webhooks.NewHandler(testSecret, webhooks.WithFallback(func(context.Context, *webhooks.Event) error {
+		return errors.New("database unavailable")
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, signedRequest(t, deliveredEvent, time.Now(), testSecret))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 got %d", w.Code)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(deliveredEvent)
+	ts := time.Now()
+	sig := webhooks.Sign(testSecret, ts, body)
+	stamp := strconv.FormatInt(ts.Unix(), 10)
+
+	if err := webhooks.Verify(testSecret, stamp, sig, body, time.Minute); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := webhooks.Verify("other", stamp, sig, body, time.Minute); !errors.Is(err, webhooks.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+	if err := webhooks.Verify(testSecret, "", sig, body, time.Minute); !errors.Is(err, webhooks.ErrMissingSignature) {
+		t.Errorf("expected ErrMissingSignature, got %v", err)
+	}
+	if err := webhooks.Verify(testSecret, "yesterday", sig, body, time.Minute); !errors.Is(err, webhooks.ErrInvalidTimestamp) {
+		t.Errorf("expected ErrInvalidTimestamp, got %v", err)
+	}
+	old := strconv.FormatInt(ts.Add(-2*time.Minute).Unix(), 10)
+	if err := webhooks.Verify(testSecret, old, sig, body, time.Minute); !errors.Is(err, webhooks.ErrStaleTimestamp) {
+		t.Errorf("expected ErrStaleTimestamp, got %v", err)
+	}
+}
+
+func TestEventWithoutTimestamp(t *testing.T) {
+	var e webhooks.Event
+	if err := json.Unmarshal([]byte(`{"event_id":"1","metric":"sent"}`), &e); err != nil {
+		t.Fatal(err)
+	}
+	if !e.Timestamp.IsZero() {
+		t.Errorf("expected the zero time, got %v", e.Timestamp)
+	}
+}